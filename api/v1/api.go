@@ -1,5 +1,24 @@
 package v1
 
-import "github.com/gowool/echox/api"
+import (
+	"context"
 
-var Info = api.CRUDInfo{Area: "admin", Version: "v1"}
+	"github.com/gowool/echox/api"
+)
+
+var (
+	Info       = api.CRUDInfo{Area: "admin", Version: "v1"}
+	PublicInfo = api.CRUDInfo{Area: "public", Version: "v1"}
+)
+
+type revisionMessageKey struct{}
+
+// withRevisionMessage passes the message of the request body to the savers, which get the decoded model only.
+func withRevisionMessage(ctx context.Context, message string) context.Context {
+	return context.WithValue(ctx, revisionMessageKey{}, message)
+}
+
+func revisionMessage(ctx context.Context) string {
+	message, _ := ctx.Value(revisionMessageKey{}).(string)
+	return message
+}
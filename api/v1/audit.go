@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+// Audit is the read only api of the audit log, the entries are filtered and sorted by the list criteria.
+type Audit struct {
+	Info api.CRUDInfo
+	List api.List[model.AuditEntry]
+	Read api.Read[model.AuditEntry, int64]
+}
+
+func NewAudit(repo repository.AuditLog, errorTransformer api.ErrorTransformerFunc, options ...api.Option) Audit {
+	opts := make([]api.Option, 0, len(options)+2)
+	opts = append(opts, options...)
+	opts = append(opts, api.WithPath("/audit"), api.WithAddTags("audit"))
+
+	op := api.Operation(opts...)
+
+	return Audit{
+		Info: Info,
+		List: api.NewList(repo.FindAndCount, errorTransformer, op(api.WithSummary("Get audit entries"))),
+		Read: api.NewRead(repo.FindByID, errorTransformer, op(api.WithSummary("Get audit entry"), api.WithAddPath("/{id}"))),
+	}
+}
+
+func (h Audit) Area() string {
+	return h.Info.Area
+}
+
+func (h Audit) Version() string {
+	return h.Info.Version
+}
+
+func (h Audit) Register(e *echo.Echo, humaAPI huma.API) {
+	h.List.Register(e, humaAPI)
+	h.Read.Register(e, humaAPI)
+}
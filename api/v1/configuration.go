@@ -12,16 +12,18 @@ import (
 )
 
 type ConfigurationBody struct {
-	Debug                 *bool                    `json:"debug,omitempty" yaml:"debug,omitempty" required:"true"`
-	Multisite             *model.MultisiteStrategy `json:"multisite,omitempty" yaml:"multisite,omitempty" required:"false" enum:"host,host-by-locale,host-with-path,host-with-path-by-locale"`
-	FallbackLocale        *string                  `json:"fallbackLocale,omitempty" yaml:"fallbackLocale,omitempty" required:"false"`
-	IgnoreRequestPatterns *[]string                `json:"ignoreRequestPatterns,omitempty" yaml:"ignoreRequestPatterns,omitempty" required:"false"`
-	IgnoreRequestURIs     *[]string                `json:"ignoreRequestURIs,omitempty" yaml:"ignoreRequestURIs,omitempty" required:"false"`
-	SiteSkippers          *model.Skippers          `json:"siteSkippers,omitempty" yaml:"siteSkippers,omitempty" required:"false"`
-	PageSkippers          *model.Skippers          `json:"pageSkippers,omitempty" yaml:"pageSkippers,omitempty" required:"false"`
-	LoggerSkippers        *model.Skippers          `json:"loggerSkippers,omitempty" yaml:"loggerSkippers,omitempty" required:"false"`
-	CatchErrors           *map[string][]int        `json:"catchErrors,omitempty" yaml:"catchErrors,omitempty" required:"false"`
-	Additional            *map[string]string       `json:"additional,omitempty" yaml:"additional,omitempty" required:"false"`
+	Debug                 *bool                       `json:"debug,omitempty" yaml:"debug,omitempty" required:"true"`
+	Multisite             *model.MultisiteStrategy    `json:"multisite,omitempty" yaml:"multisite,omitempty" required:"false" enum:"host,host-by-locale,host-with-path,host-with-path-by-locale"`
+	FallbackLocale        *string                     `json:"fallbackLocale,omitempty" yaml:"fallbackLocale,omitempty" required:"false"`
+	IgnoreRequestPatterns *[]string                   `json:"ignoreRequestPatterns,omitempty" yaml:"ignoreRequestPatterns,omitempty" required:"false"`
+	IgnoreRequestURIs     *[]string                   `json:"ignoreRequestURIs,omitempty" yaml:"ignoreRequestURIs,omitempty" required:"false"`
+	SiteSkippers          *model.Skippers             `json:"siteSkippers,omitempty" yaml:"siteSkippers,omitempty" required:"false"`
+	PageSkippers          *model.Skippers             `json:"pageSkippers,omitempty" yaml:"pageSkippers,omitempty" required:"false"`
+	LoggerSkippers        *model.Skippers             `json:"loggerSkippers,omitempty" yaml:"loggerSkippers,omitempty" required:"false"`
+	CatchErrors           *map[string][]int           `json:"catchErrors,omitempty" yaml:"catchErrors,omitempty" required:"false"`
+	Additional            *map[string]string          `json:"additional,omitempty" yaml:"additional,omitempty" required:"false"`
+	PageRevisions         *model.RevisionRetention    `json:"pageRevisions,omitempty" yaml:"pageRevisions,omitempty" required:"false"`
+	Workflow              *[]model.WorkflowTransition `json:"workflow,omitempty" yaml:"workflow,omitempty" required:"false"`
 }
 
 type Configuration struct {
@@ -99,6 +101,12 @@ func (h Configuration) save(ctx context.Context, in *api.CreateInput[Configurati
 	if in.Body.Additional != nil {
 		cfg.Additional = *in.Body.Additional
 	}
+	if in.Body.PageRevisions != nil {
+		cfg.PageRevisions = in.Body.PageRevisions
+	}
+	if in.Body.Workflow != nil {
+		cfg.Workflow = *in.Body.Workflow
+	}
 
 	err = h.repo.Save(ctx, &cfg)
 
@@ -3,47 +3,165 @@ package v1
 import (
 	"context"
 
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/cr"
 	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
 
+	"github.com/gowool/pages"
 	"github.com/gowool/pages/model"
 	"github.com/gowool/pages/repository"
 )
 
 type MenuBody struct {
-	NodeID  *int64 `json:"nodeID,omitempty" yaml:"nodeID,omitempty" required:"false"`
-	Name    string `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
-	Handle  string `json:"handle,omitempty" yaml:"handle,omitempty" required:"false"`
-	Enabled bool   `json:"enabled,omitempty" yaml:"enabled,omitempty" required:"false"`
+	SiteID   *int64 `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"false" doc:"Site of the menu, a global menu when empty"`
+	NodeID   *int64 `json:"nodeID,omitempty" yaml:"nodeID,omitempty" required:"false"`
+	Name     string `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
+	Handle   string `json:"handle,omitempty" yaml:"handle,omitempty" required:"false"`
+	Enabled  bool   `json:"enabled,omitempty" yaml:"enabled,omitempty" required:"false"`
+	Source   string `json:"source,omitempty" yaml:"source,omitempty" required:"false" doc:"Name of the menu source building the nodes, e.g. pages"`
+	RootPage string `json:"rootPage,omitempty" yaml:"rootPage,omitempty" required:"false" doc:"Root page ID or alias of the generated menu"`
+	Depth    int    `json:"depth,omitempty" yaml:"depth,omitempty" required:"false" minimum:"0" doc:"Max depth of the generated menu, 0 means unlimited"`
 }
 
 func (dto MenuBody) Decode(_ context.Context, m *model.Menu) error {
+	m.SiteID = dto.SiteID
 	m.NodeID = dto.NodeID
 	m.Name = dto.Name
 	m.Handle = dto.Handle
 	m.Enabled = dto.Enabled
+	m.Source = dto.Source
+	m.RootPage = dto.RootPage
+	m.Depth = dto.Depth
 	return nil
 }
 
+type MenuListInput struct {
+	api.ListInput
+	SiteID int64 `query:"siteID" doc:"Menus of the site only"`
+	Global bool  `query:"global" doc:"Global menus only"`
+}
+
+func (in *MenuListInput) criteria() *cr.Criteria {
+	criteria := cr.New(in.Filter, in.Sort).SetOffset((in.Page - 1) * in.Limit).SetSize(in.Limit)
+
+	var cond cr.Condition
+	switch {
+	case in.Global:
+		cond = cr.Condition{Column: "site_id", Operator: cr.OpIS, Value: nil}
+	case in.SiteID > 0:
+		cond = cr.Condition{Column: "site_id", Operator: cr.OpEqual, Value: in.SiteID}
+	default:
+		return criteria
+	}
+
+	if criteria.Filter.IsEmpty() {
+		return criteria.SetFilter(cr.Filter{Conditions: []any{cond}})
+	}
+	return criteria.SetFilter(cr.Filter{Conditions: []any{criteria.Filter, cond}})
+}
+
+type MenuCloneBody struct {
+	SiteID *int64 `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"false" doc:"Target site, a global menu when empty"`
+	Handle string `json:"handle,omitempty" yaml:"handle,omitempty" required:"false" doc:"Handle of the copy, the source handle when empty"`
+}
+
+type MenuDeleteInput struct {
+	ID        int64 `path:"id"`
+	WithNodes bool  `query:"withNodes" doc:"Delete the node tree of the menu"`
+	DryRun    bool  `query:"dryRun" doc:"Report what would be deleted without deleting anything"`
+}
+
+type MenuDeleteManyInput struct {
+	WithNodes bool `query:"withNodes" doc:"Delete the node trees of the menus"`
+	DryRun    bool `query:"dryRun" doc:"Report what would be deleted without deleting anything"`
+	Body      struct {
+		IDs []int64 `json:"ids" required:"true" minItems:"1" nullable:"false"`
+	}
+}
+
 type Menu struct {
 	api.CRUD[MenuBody, MenuBody, model.Menu, int64]
+	nodeTree       pages.NodeTree
+	cloneOperation huma.Operation
 }
 
-func NewMenu(repo repository.Menu, errorTransformer api.ErrorTransformerFunc, options ...api.Option) Menu {
+func NewMenu(repo repository.Menu, nodeTree pages.NodeTree, errorTransformer api.ErrorTransformerFunc, options ...api.Option) Menu {
 	opts := make([]api.Option, 0, len(options)+2)
 	opts = append(opts, options...)
 	opts = append(opts, api.WithPath("/menus"), api.WithAddTags("menu"))
 
 	op := api.Operation(opts...)
 
+	// the handles are unique within the site like those of the cloned menus
+	withHandle := func(saver func(context.Context, *model.Menu) error) func(context.Context, *model.Menu) error {
+		return func(ctx context.Context, m *model.Menu) error {
+			if err := pages.ValidateMenuHandle(ctx, repo, *m); err != nil {
+				return validationError(err)
+			}
+			return saver(ctx, m)
+		}
+	}
+
 	return Menu{
 		CRUD: api.CRUD[MenuBody, MenuBody, model.Menu, int64]{
 			Info:       Info,
 			List:       api.NewList(repo.FindAndCount, errorTransformer, op(api.WithSummary("Get menus"))),
 			Read:       api.NewRead(repo.FindByID, errorTransformer, op(api.WithSummary("Get menu"), api.WithAddPath("/{id}"))),
-			Create:     api.NewCreate[MenuBody](repo.Create, errorTransformer, op(api.WithPost, api.WithSummary("Create menu"))),
-			Update:     api.NewUpdate[MenuBody](repo.FindByID, repo.Update, errorTransformer, op(api.WithPut, api.WithSummary("Update menu"), api.WithAddPath("/{id}"))),
-			Delete:     api.NewDelete(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete menu"), api.WithAddPath("/{id}"))),
-			DeleteMany: api.NewDeleteMany(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete menus"))),
+			Create:     api.NewCreate[MenuBody](withHandle(repo.Create), errorTransformer, op(api.WithPost, api.WithSummary("Create menu"))),
+			Update:     api.NewUpdate[MenuBody](repo.FindByID, withHandle(repo.Update), errorTransformer, op(api.WithPut, api.WithSummary("Update menu"), api.WithAddPath("/{id}"))),
+			Delete:     api.NewDelete(repo.Delete, errorTransformer, op(api.WithDelete, api.WithOK, api.WithSummary("Delete menu"), api.WithAddPath("/{id}"))),
+			DeleteMany: api.NewDeleteMany(repo.Delete, errorTransformer, op(api.WithDelete, api.WithOK, api.WithSummary("Delete menus"))),
+		},
+		nodeTree:       nodeTree,
+		cloneOperation: op(api.WithPost, api.WithCreated, api.WithSummary("Clone menu"), api.WithAddPath("/{id}/clone")),
+	}
+}
+
+func (h Menu) Register(e *echo.Echo, humaAPI huma.API) {
+	api.Register(humaAPI, api.Transform(h.List.ErrorTransformer, h.list), h.List.Operation)
+	h.Read.Register(e, humaAPI)
+	h.Create.Register(e, humaAPI)
+	h.Update.Register(e, humaAPI)
+	api.Register(humaAPI, api.Transform(h.Delete.ErrorTransformer, h.delete), h.Delete.Operation)
+	api.Register(humaAPI, api.Transform(h.DeleteMany.ErrorTransformer, h.deleteMany), h.DeleteMany.Operation)
+	api.Register(humaAPI, api.Transform(h.Create.ErrorTransformer, h.clone), h.cloneOperation)
+}
+
+func (h Menu) list(ctx context.Context, in *MenuListInput) (*api.Response[api.ListOutput[model.Menu]], error) {
+	items, total, err := h.List.Finder(ctx, in.criteria())
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[api.ListOutput[model.Menu]]{
+		Body: api.ListOutput[model.Menu]{
+			ListInput: in.ListInput,
+			Items:     items,
+			Total:     total,
 		},
+	}, nil
+}
+
+func (h Menu) clone(ctx context.Context, in *api.UpdateInput[MenuCloneBody, int64]) (*api.Response[model.Menu], error) {
+	menu, err := h.nodeTree.CloneMenu(ctx, in.ID, in.Body.SiteID, in.Body.Handle)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	return &api.Response[model.Menu]{Body: menu}, nil
+}
+
+func (h Menu) delete(ctx context.Context, in *MenuDeleteInput) (*api.Response[pages.NodeDeleteReport], error) {
+	report, err := h.nodeTree.DeleteMenus(ctx, pages.MenuDeleteOptions{WithNodes: in.WithNodes, DryRun: in.DryRun}, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[pages.NodeDeleteReport]{Body: report}, nil
+}
+
+func (h Menu) deleteMany(ctx context.Context, in *MenuDeleteManyInput) (*api.Response[pages.NodeDeleteReport], error) {
+	report, err := h.nodeTree.DeleteMenus(ctx, pages.MenuDeleteOptions{WithNodes: in.WithNodes, DryRun: in.DryRun}, in.Body.IDs...)
+	if err != nil {
+		return nil, err
 	}
+	return &api.Response[pages.NodeDeleteReport]{Body: report}, nil
 }
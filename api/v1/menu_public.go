@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type PublicMenuInput struct {
+	Handle        string `path:"handle"`
+	SiteID        int64  `query:"siteID" doc:"Site of the menu when the site is not selected by the request host"`
+	Path          string `query:"path" doc:"Current url path the current and ancestor flags are evaluated against"`
+	MaxDepth      int    `query:"maxDepth" minimum:"0" doc:"Max depth of the tree, 0 means unlimited"`
+	StartLevel    int    `query:"startLevel" minimum:"0" doc:"Level of the current branch used as the root node"`
+	CurrentBranch bool   `query:"currentBranch" doc:"Include the children of the current branch only"`
+}
+
+type PublicMenuNode struct {
+	ID                 int64             `json:"id" yaml:"id" required:"true"`
+	Name               string            `json:"name" yaml:"name" required:"true"`
+	Label              string            `json:"label,omitempty" yaml:"label,omitempty" required:"false"`
+	URL                string            `json:"url,omitempty" yaml:"url,omitempty" required:"false"`
+	PageID             *int64            `json:"pageID,omitempty" yaml:"pageID,omitempty" required:"false"`
+	Current            bool              `json:"current,omitempty" yaml:"current,omitempty" required:"false"`
+	Ancestor           bool              `json:"ancestor,omitempty" yaml:"ancestor,omitempty" required:"false"`
+	Attributes         map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty" required:"false"`
+	LinkAttributes     map[string]string `json:"linkAttributes,omitempty" yaml:"linkAttributes,omitempty" required:"false"`
+	ChildrenAttributes map[string]string `json:"childrenAttributes,omitempty" yaml:"childrenAttributes,omitempty" required:"false"`
+	LabelAttributes    map[string]string `json:"labelAttributes,omitempty" yaml:"labelAttributes,omitempty" required:"false"`
+	Children           []PublicMenuNode  `json:"children,omitempty" yaml:"children,omitempty" required:"false"`
+}
+
+func NewPublicMenuNode(n *model.Node) PublicMenuNode {
+	var pageID *int64
+	if n.Page != nil {
+		pageID = &n.Page.ID
+	}
+	return PublicMenuNode{
+		ID:                 n.ID,
+		Name:               n.Name,
+		Label:              n.Label,
+		URL:                n.URI,
+		PageID:             pageID,
+		Current:            n.Current,
+		Ancestor:           n.Ancestor,
+		Attributes:         n.Attributes,
+		LinkAttributes:     n.LinkAttributes,
+		ChildrenAttributes: n.ChildrenAttributes,
+		LabelAttributes:    n.LabelAttributes,
+		Children:           internal.Map(n.Children, NewPublicMenuNode),
+	}
+}
+
+type PublicMenuBody struct {
+	ID     int64           `json:"id" yaml:"id" required:"true"`
+	Name   string          `json:"name" yaml:"name" required:"true"`
+	Handle string          `json:"handle" yaml:"handle" required:"true"`
+	Node   *PublicMenuNode `json:"node,omitempty" yaml:"node,omitempty" required:"false"`
+}
+
+type PublicMenu struct {
+	errorTransformer api.ErrorTransformerFunc
+	menu             pages.Menu
+	menuCache        pages.MenuCache
+	siteRepo         repository.Site
+	pageRepo         repository.Page
+	op               func(options ...api.Option) huma.Operation
+}
+
+func NewPublicMenu(
+	menu pages.Menu,
+	menuCache pages.MenuCache,
+	siteRepo repository.Site,
+	pageRepo repository.Page,
+	errorTransformer api.ErrorTransformerFunc,
+	options ...api.Option,
+) PublicMenu {
+	opts := make([]api.Option, 0, len(options)+2)
+	opts = append(opts, options...)
+	opts = append(opts, api.WithPath("/menus"), api.WithAddTags("menu"))
+
+	return PublicMenu{
+		errorTransformer: errorTransformer,
+		menu:             menu,
+		menuCache:        menuCache,
+		siteRepo:         siteRepo,
+		pageRepo:         pageRepo,
+		op:               api.Operation(opts...),
+	}
+}
+
+func (PublicMenu) Area() string {
+	return PublicInfo.Area
+}
+
+func (PublicMenu) Version() string {
+	return PublicInfo.Version
+}
+
+func (h PublicMenu) Register(_ *echo.Echo, humaAPI huma.API) {
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.get), h.op(api.WithSummary("Get resolved menu"), api.WithAddPath("/{handle}")))
+}
+
+func (h PublicMenu) get(ctx context.Context, in *PublicMenuInput) (*api.Response[PublicMenuBody], error) {
+	ctx, cacheable, err := h.context(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		key  string
+		body PublicMenuBody
+	)
+	if cacheable {
+		key = h.menuCache.Key(ctx, in.Handle, "json", in.MaxDepth, in.StartLevel, in.CurrentBranch)
+
+		if h.menuCache.Get(ctx, key, &body) {
+			return &api.Response[PublicMenuBody]{Body: body}, nil
+		}
+	}
+
+	options := []pages.MenuOption{pages.WithMenuDisplay()}
+	if in.MaxDepth > 0 {
+		options = append(options, pages.WithMenuMaxDepth(in.MaxDepth))
+	}
+	if in.StartLevel > 0 {
+		options = append(options, pages.WithMenuStartLevel(in.StartLevel))
+	}
+	if in.CurrentBranch {
+		options = append(options, pages.WithMenuOnlyCurrentBranch())
+	}
+
+	m, err := h.menu.Get(ctx, in.Handle, options...)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Enabled {
+		return nil, pages.ErrMenuNotFound
+	}
+
+	body = PublicMenuBody{ID: m.ID, Name: m.Name, Handle: m.Handle}
+	if m.Node != nil {
+		node := NewPublicMenuNode(m.Node)
+		body.Node = &node
+	}
+
+	if cacheable {
+		h.menuCache.Set(ctx, key, body, m)
+	}
+	return &api.Response[PublicMenuBody]{Body: body}, nil
+}
+
+// context selects the site and the current page for the matcher.
+// The menu is cacheable unless the path resolves to no page: the path comes from the client,
+// so it is replaced by the URL of the page in the cache key and never keyed as is.
+func (h PublicMenu) context(ctx context.Context, in *PublicMenuInput) (context.Context, bool, error) {
+	site := pages.CtxSite(ctx)
+	if site == nil && in.SiteID > 0 {
+		m, err := h.siteRepo.FindByID(ctx, in.SiteID)
+		if err != nil {
+			return nil, false, err
+		}
+		if !m.IsEnabled(time.Now().UTC()) {
+			return nil, false, pages.ErrSiteNotFound
+		}
+		site = &m
+		ctx = pages.WithSite(ctx, site)
+	}
+
+	if in.Path == "" {
+		return ctx, true, nil
+	}
+
+	ctx = pages.WithURL(ctx, url.URL{Path: in.Path})
+
+	if site == nil {
+		return ctx, false, nil
+	}
+
+	prefix := strings.TrimSuffix(site.RelativePath, "/")
+	path := strings.TrimPrefix(in.Path, prefix)
+	if path == "" {
+		path = "/"
+	}
+
+	page, err := h.pageRepo.FindByURL(ctx, site.ID, path, time.Now().UTC())
+	if err != nil {
+		if pages.IsOneOfNotFound(err) {
+			return ctx, false, nil
+		}
+		return nil, false, err
+	}
+
+	ctx = pages.WithPage(ctx, &page)
+	ctx = pages.WithURL(ctx, url.URL{Path: prefix + page.URL})
+	return ctx, true, nil
+}
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func TestPublicMenuContextKeysResolvedPagesOnly(t *testing.T) {
+	pageRepo := memory.NewPageRepository(pages.ErrPageNotFound)
+	published := internal.Ptr(time.Now().Add(-time.Hour))
+	blog := model.Page{SiteID: 1, Name: "Blog", Pattern: model.PageCMS, URL: "/blog", Published: published}
+	if err := pageRepo.Create(context.Background(), &blog); err != nil {
+		t.Fatal(err)
+	}
+
+	h := PublicMenu{pageRepo: pageRepo}
+	ctx := pages.WithSite(context.Background(), &model.Site{ID: 1, RelativePath: "/en"})
+
+	tests := []struct {
+		path      string
+		cacheable bool
+		url       string
+	}{
+		{path: "", cacheable: true},
+		{path: "/en/blog", cacheable: true, url: "/en/blog"},
+		{path: "/en/blog?utm=1", cacheable: false},
+		{path: "/en/random-123", cacheable: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, cacheable, err := h.context(ctx, &PublicMenuInput{Path: tt.path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cacheable != tt.cacheable {
+				t.Errorf("cacheable = %v, want %v", cacheable, tt.cacheable)
+			}
+			if tt.cacheable && pages.CtxURL(got).Path != tt.url {
+				t.Errorf("url = %q, want %q", pages.CtxURL(got).Path, tt.url)
+			}
+		})
+	}
+
+	if _, cacheable, _ := h.context(context.Background(), &PublicMenuInput{Path: "/blog"}); cacheable {
+		t.Error("the path is keyed without a site")
+	}
+}
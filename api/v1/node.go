@@ -3,8 +3,11 @@ package v1
 import (
 	"context"
 
+	"github.com/danielgtaylor/huma/v2"
 	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
 
+	"github.com/gowool/pages"
 	"github.com/gowool/pages/model"
 	"github.com/gowool/pages/repository"
 )
@@ -14,6 +17,7 @@ type NodeBody struct {
 	Name               string            `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
 	Label              string            `json:"label,omitempty" yaml:"label,omitempty" required:"false"`
 	URI                string            `json:"uri,omitempty" yaml:"uri,omitempty" required:"false"`
+	PageID             *int64            `json:"pageID,omitempty" yaml:"pageID,omitempty" required:"false"`
 	Position           int               `json:"position,omitempty" yaml:"position,omitempty" required:"false"`
 	DisplayChildren    bool              `json:"displayChildren,omitempty" yaml:"displayChildren,omitempty" required:"false"`
 	Display            bool              `json:"display,omitempty" yaml:"display,omitempty" required:"false"`
@@ -29,6 +33,7 @@ func (dto NodeBody) Decode(_ context.Context, m *model.Node) error {
 	m.Name = dto.Name
 	m.Label = dto.Label
 	m.URI = dto.URI
+	m.PageID = dto.PageID
 	m.Position = dto.Position
 	m.DisplayChildren = dto.DisplayChildren
 	m.Display = dto.Display
@@ -40,11 +45,38 @@ func (dto NodeBody) Decode(_ context.Context, m *model.Node) error {
 	return nil
 }
 
+type NodeMoveBody struct {
+	ParentID int64 `json:"parentID,omitempty" yaml:"parentID,omitempty" required:"false"`
+	Position int   `json:"position,omitempty" yaml:"position,omitempty" required:"false" minimum:"0"`
+}
+
+type NodeReorderBody struct {
+	ParentID int64   `json:"parentID,omitempty" yaml:"parentID,omitempty" required:"true"`
+	IDs      []int64 `json:"ids" yaml:"ids" required:"true" minItems:"1" nullable:"false"`
+}
+
+type NodeDeleteInput struct {
+	ID       int64 `path:"id"`
+	WithMenu bool  `query:"withMenu" doc:"Delete the menu whose root node is deleted"`
+	DryRun   bool  `query:"dryRun" doc:"Report what would be deleted without deleting anything"`
+}
+
+type NodeDeleteManyInput struct {
+	WithMenu bool `query:"withMenu" doc:"Delete the menus whose root nodes are deleted"`
+	DryRun   bool `query:"dryRun" doc:"Report what would be deleted without deleting anything"`
+	Body     struct {
+		IDs []int64 `json:"ids" required:"true" minItems:"1" nullable:"false"`
+	}
+}
+
 type Node struct {
 	api.CRUD[NodeBody, NodeBody, model.Node, int64]
+	nodeTree         pages.NodeTree
+	moveOperation    huma.Operation
+	reorderOperation huma.Operation
 }
 
-func NewNode(repo repository.Node, errorTransformer api.ErrorTransformerFunc, options ...api.Option) Node {
+func NewNode(repo repository.Node, nodeTree pages.NodeTree, errorTransformer api.ErrorTransformerFunc, options ...api.Option) Node {
 	opts := make([]api.Option, 0, len(options)+2)
 	opts = append(opts, options...)
 	opts = append(opts, api.WithPath("/nodes"), api.WithAddTags("node"))
@@ -56,10 +88,61 @@ func NewNode(repo repository.Node, errorTransformer api.ErrorTransformerFunc, op
 			Info:       Info,
 			List:       api.NewList(repo.FindAndCount, errorTransformer, op(api.WithSummary("Get nodes"))),
 			Read:       api.NewRead(repo.FindByID, errorTransformer, op(api.WithSummary("Get node"), api.WithAddPath("/{id}"))),
-			Create:     api.NewCreate[NodeBody](repo.Create, errorTransformer, op(api.WithPost, api.WithSummary("Create node"))),
-			Update:     api.NewUpdate[NodeBody](repo.FindByID, repo.Update, errorTransformer, op(api.WithPut, api.WithSummary("Update node"), api.WithAddPath("/{id}"))),
-			Delete:     api.NewDelete(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete node"), api.WithAddPath("/{id}"))),
-			DeleteMany: api.NewDeleteMany(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete nodes"))),
+			Create:     api.NewCreate[NodeBody](nodeTree.Create, errorTransformer, op(api.WithPost, api.WithSummary("Create node"))),
+			Update:     api.NewUpdate[NodeBody](repo.FindByID, nodeTree.Update, errorTransformer, op(api.WithPut, api.WithSummary("Update node"), api.WithAddPath("/{id}"))),
+			Delete:     api.NewDelete(deleteNodes(nodeTree), errorTransformer, op(api.WithDelete, api.WithOK, api.WithSummary("Delete node"), api.WithAddPath("/{id}"))),
+			DeleteMany: api.NewDeleteMany(deleteNodes(nodeTree), errorTransformer, op(api.WithDelete, api.WithOK, api.WithSummary("Delete nodes"))),
 		},
+		nodeTree:         nodeTree,
+		moveOperation:    op(api.WithPost, api.WithNoContent, api.WithSummary("Move node"), api.WithAddPath("/{id}/move")),
+		reorderOperation: op(api.WithPut, api.WithNoContent, api.WithSummary("Reorder nodes"), api.WithAddPath("/reorder")),
+	}
+}
+
+func (h Node) Register(e *echo.Echo, humaAPI huma.API) {
+	h.Update.Saver = func(ctx context.Context, m *model.Node) error {
+		return cycleError(h.nodeTree.Update(ctx, m))
+	}
+
+	h.List.Register(e, humaAPI)
+	h.Read.Register(e, humaAPI)
+	h.Create.Register(e, humaAPI)
+	h.Update.Register(e, humaAPI)
+	api.Register(humaAPI, api.Transform(h.Delete.ErrorTransformer, h.delete), h.Delete.Operation)
+	api.Register(humaAPI, api.Transform(h.DeleteMany.ErrorTransformer, h.deleteMany), h.DeleteMany.Operation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.move), h.moveOperation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.reorder), h.reorderOperation)
+}
+
+func (h Node) move(ctx context.Context, in *api.UpdateInput[NodeMoveBody, int64]) (*struct{}, error) {
+	err := h.nodeTree.Move(ctx, in.ID, in.Body.ParentID, in.Body.Position)
+	return nil, cycleError(err)
+}
+
+func (h Node) reorder(ctx context.Context, in *api.CreateInput[NodeReorderBody]) (*struct{}, error) {
+	err := h.nodeTree.Reorder(ctx, in.Body.ParentID, in.Body.IDs)
+	return nil, err
+}
+
+func (h Node) delete(ctx context.Context, in *NodeDeleteInput) (*api.Response[pages.NodeDeleteReport], error) {
+	report, err := h.nodeTree.Delete(ctx, pages.NodeDeleteOptions{WithMenu: in.WithMenu, DryRun: in.DryRun}, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[pages.NodeDeleteReport]{Body: report}, nil
+}
+
+func (h Node) deleteMany(ctx context.Context, in *NodeDeleteManyInput) (*api.Response[pages.NodeDeleteReport], error) {
+	report, err := h.nodeTree.Delete(ctx, pages.NodeDeleteOptions{WithMenu: in.WithMenu, DryRun: in.DryRun}, in.Body.IDs...)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[pages.NodeDeleteReport]{Body: report}, nil
+}
+
+func deleteNodes(nodeTree pages.NodeTree) func(context.Context, ...int64) error {
+	return func(ctx context.Context, ids ...int64) error {
+		_, err := nodeTree.Delete(ctx, pages.NodeDeleteOptions{}, ids...)
+		return err
 	}
 }
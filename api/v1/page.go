@@ -2,15 +2,19 @@ package v1
 
 import (
 	"context"
+	"fmt"
 	"maps"
 	"slices"
 	"strings"
 	"time"
 
 	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/cr"
 	"github.com/gowool/echox/api"
 	"github.com/labstack/echo/v4"
 
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal"
 	"github.com/gowool/pages/model"
 	"github.com/gowool/pages/repository"
 )
@@ -34,8 +38,8 @@ type PageBody struct {
 	Headers     map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" required:"false"`
 	Metas       []model.Meta      `json:"metas,omitempty" yaml:"metas,omitempty" required:"false"`
 	Metadata    map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty" required:"false"`
-	Published   *time.Time        `json:"published,omitempty" yaml:"published,omitempty" required:"false"`
 	Expired     *time.Time        `json:"expired,omitempty" yaml:"expired,omitempty" required:"false"`
+	Message     string            `json:"message,omitempty" yaml:"message,omitempty" required:"false" doc:"Message of the revision"`
 }
 
 func (dto PageBody) Decode(_ context.Context, m *model.Page) error {
@@ -57,18 +61,100 @@ func (dto PageBody) Decode(_ context.Context, m *model.Page) error {
 	m.Headers = dto.Headers
 	m.Metas = dto.Metas
 	m.Metadata = dto.Metadata
-	m.Published = dto.Published
 	m.Expired = dto.Expired
 	return nil
 }
 
+type PageTreeInput struct {
+	SiteID int64 `query:"siteID" required:"true"`
+}
+
+type PageTreeItem struct {
+	model.Page
+	Children []PageTreeItem `json:"children,omitempty" yaml:"children,omitempty" required:"false"`
+}
+
+func NewPageTreeItems(data []model.Page) []PageTreeItem {
+	return internal.Map(data, func(item model.Page) PageTreeItem {
+		return PageTreeItem{Page: item, Children: NewPageTreeItems(item.Children)}
+	})
+}
+
+type PageMoveBody struct {
+	ParentID *int64 `json:"parentID,omitempty" yaml:"parentID,omitempty" required:"false"`
+	Position int    `json:"position,omitempty" yaml:"position,omitempty" required:"false" minimum:"0"`
+}
+
+type PageReorderBody struct {
+	SiteID   int64   `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"true"`
+	ParentID *int64  `json:"parentID,omitempty" yaml:"parentID,omitempty" required:"false"`
+	IDs      []int64 `json:"ids" yaml:"ids" required:"true" minItems:"1" nullable:"false"`
+}
+
+type PageRevisionListInput struct {
+	ID int64 `path:"id"`
+	api.ListInput
+}
+
+func (in *PageRevisionListInput) criteria() *cr.Criteria {
+	criteria := cr.New(in.Filter, in.Sort).SetOffset((in.Page - 1) * in.Limit).SetSize(in.Limit)
+	cond := cr.Condition{Column: "page_id", Operator: cr.OpEqual, Value: in.ID}
+
+	if criteria.Filter.IsEmpty() {
+		return criteria.SetFilter(cr.Filter{Conditions: []any{cond}})
+	}
+	return criteria.SetFilter(cr.Filter{Conditions: []any{criteria.Filter, cond}})
+}
+
+type PageRevisionDiffInput struct {
+	ID   int64 `path:"id"`
+	From int64 `query:"from" required:"true" doc:"Old revision"`
+	To   int64 `query:"to" required:"true" doc:"New revision"`
+}
+
+type PageRevisionDiffBody struct {
+	From    int64                   `json:"from" yaml:"from" required:"true"`
+	To      int64                   `json:"to" yaml:"to" required:"true"`
+	Changes []pages.PageFieldChange `json:"changes" yaml:"changes" required:"true"`
+}
+
+type PageRestoreInput struct {
+	ID         int64 `path:"id"`
+	RevisionID int64 `path:"revisionID"`
+	Body       struct {
+		Message string `json:"message,omitempty" yaml:"message,omitempty" required:"false" doc:"Message of the revision"`
+	}
+}
+
 type Page struct {
 	api.CRUD[PageBody, PageBody, model.Page, int64]
-	cfgRepo         repository.Configuration
-	hybridOperation huma.Operation
+	pageRepo           repository.Page
+	pageTree           pages.PageTree
+	pageValidator      pages.PageValidator
+	workflow           pages.PageWorkflow
+	revisions          pages.PageRevisions
+	revisionRepo       repository.PageRevision
+	cfgRepo            repository.Configuration
+	hybridOperation    huma.Operation
+	treeOperation      huma.Operation
+	moveOperation      huma.Operation
+	reorderOperation   huma.Operation
+	revisionsOperation huma.Operation
+	diffOperation      huma.Operation
+	restoreOperation   huma.Operation
 }
 
-func NewPage(pageRepo repository.Page, cfgRepo repository.Configuration, errorTransformer api.ErrorTransformerFunc, options ...api.Option) Page {
+func NewPage(
+	pageRepo repository.Page,
+	pageTree pages.PageTree,
+	pageValidator pages.PageValidator,
+	workflow pages.PageWorkflow,
+	revisions pages.PageRevisions,
+	revisionRepo repository.PageRevision,
+	cfgRepo repository.Configuration,
+	errorTransformer api.ErrorTransformerFunc,
+	options ...api.Option,
+) Page {
 	opts := make([]api.Option, 0, len(options)+2)
 	opts = append(opts, options...)
 	opts = append(opts, api.WithPath("/pages"), api.WithAddTags("page"))
@@ -80,19 +166,146 @@ func NewPage(pageRepo repository.Page, cfgRepo repository.Configuration, errorTr
 			Info:       Info,
 			List:       api.NewList(pageRepo.FindAndCount, errorTransformer, op(api.WithSummary("Get pages"))),
 			Read:       api.NewRead(pageRepo.FindByID, errorTransformer, op(api.WithSummary("Get page"), api.WithAddPath("/{id}"))),
-			Create:     api.NewCreate[PageBody](pageRepo.Create, errorTransformer, op(api.WithPost, api.WithSummary("Create page"))),
-			Update:     api.NewUpdate[PageBody](pageRepo.FindByID, pageRepo.Update, errorTransformer, op(api.WithPut, api.WithSummary("Update page"), api.WithAddPath("/{id}"))),
+			Create:     api.NewCreate[PageBody](pageTree.Create, errorTransformer, op(api.WithPost, api.WithSummary("Create page"))),
+			Update:     api.NewUpdate[PageBody](pageRepo.FindByID, pageTree.Update, errorTransformer, op(api.WithPut, api.WithSummary("Update page"), api.WithAddPath("/{id}"))),
 			Delete:     api.NewDelete(pageRepo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete page"), api.WithAddPath("/{id}"))),
 			DeleteMany: api.NewDeleteMany(pageRepo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete pages"))),
 		},
-		cfgRepo:         cfgRepo,
-		hybridOperation: op(api.WithSummary("Get hybrid patterns"), api.WithAddPath("/hybrid-patterns")),
+		pageRepo:           pageRepo,
+		pageTree:           pageTree,
+		pageValidator:      pageValidator,
+		workflow:           workflow,
+		revisions:          revisions,
+		revisionRepo:       revisionRepo,
+		cfgRepo:            cfgRepo,
+		hybridOperation:    op(api.WithSummary("Get hybrid patterns"), api.WithAddPath("/hybrid-patterns")),
+		treeOperation:      op(api.WithSummary("Get page tree"), api.WithAddPath("/tree")),
+		moveOperation:      op(api.WithPost, api.WithNoContent, api.WithSummary("Move page"), api.WithAddPath("/{id}/move")),
+		reorderOperation:   op(api.WithPut, api.WithNoContent, api.WithSummary("Reorder pages"), api.WithAddPath("/reorder")),
+		revisionsOperation: op(api.WithSummary("Get page revisions"), api.WithAddPath("/{id}/revisions")),
+		diffOperation:      op(api.WithSummary("Diff page revisions"), api.WithAddPath("/{id}/revisions/diff")),
+		restoreOperation:   op(api.WithPost, api.WithNoContent, api.WithSummary("Restore page revision"), api.WithAddPath("/{id}/revisions/{revisionID}/restore")),
 	}
 }
 
 func (h Page) Register(e *echo.Echo, humaAPI huma.API) {
-	h.CRUD.Register(e, humaAPI)
+	h.Create.Saver = h.save(e, h.pageTree.Create)
+	h.Update.Saver = h.save(e, h.pageTree.Update)
+
+	h.List.Register(e, humaAPI)
+	h.Read.Register(e, humaAPI)
+	h.Delete.Register(e, humaAPI)
+	h.DeleteMany.Register(e, humaAPI)
+	api.Register(humaAPI, api.Transform(h.Create.ErrorTransformer, h.create), h.Create.Operation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.update), h.Update.Operation)
 	api.Register(humaAPI, api.Transform(h.List.ErrorTransformer, h.HybridPatterns(e)), h.hybridOperation)
+	api.Register(humaAPI, api.Transform(h.List.ErrorTransformer, h.tree), h.treeOperation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.move(e)), h.moveOperation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.reorder(e)), h.reorderOperation)
+	api.Register(humaAPI, api.Transform(h.List.ErrorTransformer, h.revisionList), h.revisionsOperation)
+	api.Register(humaAPI, api.Transform(h.Read.ErrorTransformer, h.diff), h.diffOperation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.restore(e)), h.restoreOperation)
+}
+
+func (h Page) create(ctx context.Context, in *api.CreateInput[PageBody]) (*api.CreateResponse, error) {
+	return h.Create.Handler(withRevisionMessage(ctx, in.Body.Message), in)
+}
+
+func (h Page) update(ctx context.Context, in *api.UpdateInput[PageBody, int64]) (*struct{}, error) {
+	return h.Update.Handler(withRevisionMessage(ctx, in.Body.Message), in)
+}
+
+func (h Page) revisionList(ctx context.Context, in *PageRevisionListInput) (*api.Response[api.ListOutput[model.PageRevision]], error) {
+	items, total, err := h.revisionRepo.FindAndCount(ctx, in.criteria())
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[api.ListOutput[model.PageRevision]]{
+		Body: api.ListOutput[model.PageRevision]{
+			ListInput: in.ListInput,
+			Items:     items,
+			Total:     total,
+		},
+	}, nil
+}
+
+func (h Page) diff(ctx context.Context, in *PageRevisionDiffInput) (*api.Response[PageRevisionDiffBody], error) {
+	from, err := h.revisionRepo.FindByID(ctx, in.From)
+	if err != nil {
+		return nil, err
+	}
+	if from.PageID != in.ID {
+		return nil, pages.ErrPageRevisionNotFound
+	}
+
+	changes, err := h.revisions.Diff(ctx, in.From, in.To)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	return &api.Response[PageRevisionDiffBody]{Body: PageRevisionDiffBody{From: in.From, To: in.To, Changes: changes}}, nil
+}
+
+// restore saves the page reverted to the revision like any other edit,
+// so it is validated against the current pages and routes and recorded as a new revision.
+func (h Page) restore(e *echo.Echo) func(context.Context, *PageRestoreInput) (*struct{}, error) {
+	return func(ctx context.Context, in *PageRestoreInput) (*struct{}, error) {
+		revision, err := h.revisionRepo.FindByID(ctx, in.RevisionID)
+		if err != nil {
+			return nil, err
+		}
+		if revision.PageID != in.ID {
+			return nil, pages.ErrPageRevisionNotFound
+		}
+
+		m, err := h.revisions.Revert(ctx, in.RevisionID)
+		if err != nil {
+			return nil, err
+		}
+
+		message := in.Body.Message
+		if message == "" {
+			message = fmt.Sprintf("Restore revision %d", revision.ID)
+		}
+
+		return nil, h.save(e, h.pageTree.Update)(withRevisionMessage(ctx, message), &m)
+	}
+}
+
+func (h Page) tree(ctx context.Context, in *PageTreeInput) (*api.Response[[]PageTreeItem], error) {
+	data, err := h.pageTree.Tree(ctx, in.SiteID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[[]PageTreeItem]{Body: NewPageTreeItems(data)}, nil
+}
+
+// move and reorder save the pages like any other edit, validated, recorded as new revisions
+// and returned to the workflow.
+func (h Page) move(e *echo.Echo) func(context.Context, *api.UpdateInput[PageMoveBody, int64]) (*struct{}, error) {
+	return func(ctx context.Context, in *api.UpdateInput[PageMoveBody, int64]) (*struct{}, error) {
+		ctx = withRevisionMessage(ctx, "Move page")
+		err := h.pageTree.Move(ctx, in.ID, in.Body.ParentID, in.Body.Position, h.save(e, h.pageRepo.Update))
+		return nil, validationError(err)
+	}
+}
+
+func (h Page) reorder(e *echo.Echo) func(context.Context, *api.CreateInput[PageReorderBody]) (*struct{}, error) {
+	return func(ctx context.Context, in *api.CreateInput[PageReorderBody]) (*struct{}, error) {
+		ctx = withRevisionMessage(ctx, "Reorder pages")
+		err := h.pageTree.Reorder(ctx, in.Body.SiteID, in.Body.ParentID, in.Body.IDs, h.save(e, h.pageRepo.Update))
+		return nil, validationError(err)
+	}
+}
+
+func (h Page) save(e *echo.Echo, saver func(context.Context, *model.Page) error) func(context.Context, *model.Page) error {
+	return func(ctx context.Context, m *model.Page) error {
+		if err := h.pageValidator.Validate(ctx, *m, e.Routes()); err != nil {
+			return validationError(err)
+		}
+		return validationError(h.workflow.Save(ctx, m, func(ctx context.Context, m *model.Page) error {
+			return h.revisions.Save(ctx, m, revisionMessage(ctx), saver)
+		}))
+	}
 }
 
 type Route struct {
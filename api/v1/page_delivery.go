@@ -0,0 +1,240 @@
+package v1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
+)
+
+type PageDeliveryInput struct {
+	Host           string `query:"host" required:"true" doc:"Host of the requested url"`
+	Path           string `query:"path" required:"true" doc:"Path of the requested url"`
+	Scheme         string `query:"scheme" enum:"http,https" default:"https" doc:"Scheme of the requested url"`
+	AcceptLanguage string `header:"Accept-Language"`
+	PreviewToken   string `header:"X-Preview-Token" doc:"Token which lets the unpublished pages be delivered"`
+	IfNoneMatch    string `header:"If-None-Match"`
+}
+
+type PageDeliverySite struct {
+	ID           int64             `json:"id" yaml:"id" required:"true"`
+	Name         string            `json:"name" yaml:"name" required:"true"`
+	Title        string            `json:"title,omitempty" yaml:"title,omitempty" required:"false"`
+	Host         string            `json:"host" yaml:"host" required:"true"`
+	Locale       string            `json:"locale,omitempty" yaml:"locale,omitempty" required:"false"`
+	RelativePath string            `json:"relativePath,omitempty" yaml:"relativePath,omitempty" required:"false"`
+	URL          string            `json:"url" yaml:"url" required:"true"`
+	Metadata     map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty" required:"false"`
+}
+
+func NewPageDeliverySite(m *model.Site) *PageDeliverySite {
+	if m == nil {
+		return nil
+	}
+	return &PageDeliverySite{
+		ID:           m.ID,
+		Name:         m.Name,
+		Title:        m.Title,
+		Host:         m.Host,
+		Locale:       m.Locale,
+		RelativePath: m.RelativePath,
+		URL:          m.URL(),
+		Metadata:     m.Metadata,
+	}
+}
+
+type PageDeliveryPage struct {
+	ID          int64             `json:"id" yaml:"id" required:"true"`
+	ParentID    *int64            `json:"parentID,omitempty" yaml:"parentID,omitempty" required:"false"`
+	Name        string            `json:"name" yaml:"name" required:"true"`
+	Title       string            `json:"title,omitempty" yaml:"title,omitempty" required:"false"`
+	Alias       string            `json:"alias,omitempty" yaml:"alias,omitempty" required:"false"`
+	URL         string            `json:"url,omitempty" yaml:"url,omitempty" required:"false"`
+	Template    string            `json:"template,omitempty" yaml:"template,omitempty" required:"false"`
+	Status      int               `json:"status,omitempty" yaml:"status,omitempty" required:"false"`
+	ContentType string            `json:"contentType,omitempty" yaml:"contentType,omitempty" required:"false"`
+	Metadata    map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty" required:"false"`
+	Updated     time.Time         `json:"updated" yaml:"updated" required:"true"`
+	Published   *time.Time        `json:"published,omitempty" yaml:"published,omitempty" required:"false"`
+	Expired     *time.Time        `json:"expired,omitempty" yaml:"expired,omitempty" required:"false"`
+}
+
+func NewPageDeliveryPage(site *model.Site, m model.Page) PageDeliveryPage {
+	var url string
+	if m.URL != "" && !m.IsDynamic() {
+		url = pages.PageURI(site, m)
+	}
+	return PageDeliveryPage{
+		ID:          m.ID,
+		ParentID:    m.ParentID,
+		Name:        m.Name,
+		Title:       m.Title,
+		Alias:       m.Alias,
+		URL:         url,
+		Template:    m.Template,
+		Status:      m.Status,
+		ContentType: m.ContentType,
+		Metadata:    m.Metadata,
+		Updated:     m.Updated,
+		Published:   m.Published,
+		Expired:     m.Expired,
+	}
+}
+
+type PageDeliverySEO struct {
+	Title          string                       `json:"title,omitempty" yaml:"title,omitempty" required:"false"`
+	Metas          map[string]map[string]string `json:"metas,omitempty" yaml:"metas,omitempty" required:"false"`
+	Canonical      string                       `json:"canonical,omitempty" yaml:"canonical,omitempty" required:"false"`
+	LangAlternates map[string]string            `json:"langAlternates,omitempty" yaml:"langAlternates,omitempty" required:"false"`
+	HTMLAttributes map[string]string            `json:"htmlAttributes,omitempty" yaml:"htmlAttributes,omitempty" required:"false"`
+	OEmbedLinks    map[string]string            `json:"oembedLinks,omitempty" yaml:"oembedLinks,omitempty" required:"false"`
+}
+
+type PageDeliveryRedirect struct {
+	Status int    `json:"status" yaml:"status" required:"true"`
+	URL    string `json:"url" yaml:"url" required:"true"`
+}
+
+type PageDeliveryBody struct {
+	Site     *PageDeliverySite     `json:"site,omitempty" yaml:"site,omitempty" required:"false"`
+	Page     *PageDeliveryPage     `json:"page,omitempty" yaml:"page,omitempty" required:"false"`
+	Parents  []PageDeliveryPage    `json:"parents,omitempty" yaml:"parents,omitempty" required:"false"`
+	SEO      *PageDeliverySEO      `json:"seo,omitempty" yaml:"seo,omitempty" required:"false"`
+	Redirect *PageDeliveryRedirect `json:"redirect,omitempty" yaml:"redirect,omitempty" required:"false"`
+}
+
+func NewPageDeliveryBody(result pages.PageDeliveryResult) PageDeliveryBody {
+	body := PageDeliveryBody{Site: NewPageDeliverySite(result.Site)}
+
+	if result.Page != nil {
+		page := NewPageDeliveryPage(result.Site, *result.Page)
+		body.Page = &page
+		body.Parents = internal.Map(result.Parents, func(m model.Page) PageDeliveryPage {
+			return NewPageDeliveryPage(result.Site, m)
+		})
+	}
+
+	if result.SEO != nil {
+		body.SEO = &PageDeliverySEO{
+			Title:          result.SEO.Title(),
+			Metas:          result.SEO.Metas(),
+			Canonical:      result.SEO.LinkCanonical(),
+			LangAlternates: result.SEO.LangAlternates(),
+			HTMLAttributes: result.SEO.HTMLAttributes(),
+			OEmbedLinks:    result.SEO.OEmbedLinks(),
+		}
+	}
+
+	if result.Redirect != nil {
+		body.Redirect = &PageDeliveryRedirect{Status: result.Redirect.Status, URL: result.Redirect.URL}
+	}
+	return body
+}
+
+type PageDeliveryOutput struct {
+	ETag         string `header:"ETag"`
+	CacheControl string `header:"Cache-Control"`
+	Body         PageDeliveryBody
+}
+
+type PageDelivery struct {
+	errorTransformer api.ErrorTransformerFunc
+	delivery         pages.PageDelivery
+	verifier         pages.PreviewVerifier
+	op               func(options ...api.Option) huma.Operation
+}
+
+func NewPageDelivery(
+	delivery pages.PageDelivery,
+	verifier pages.PreviewVerifier,
+	errorTransformer api.ErrorTransformerFunc,
+	options ...api.Option,
+) PageDelivery {
+	opts := make([]api.Option, 0, len(options)+2)
+	opts = append(opts, options...)
+	opts = append(opts, api.WithPath("/pages"), api.WithAddTags("page"))
+
+	return PageDelivery{
+		errorTransformer: errorTransformer,
+		delivery:         delivery,
+		verifier:         verifier,
+		op:               api.Operation(opts...),
+	}
+}
+
+func (PageDelivery) Area() string {
+	return PublicInfo.Area
+}
+
+func (PageDelivery) Version() string {
+	return PublicInfo.Version
+}
+
+func (h PageDelivery) Register(_ *echo.Echo, humaAPI huma.API) {
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.deliver), h.op(api.WithSummary("Resolve url to page"), api.WithAddPath("/resolve")))
+}
+
+func (h PageDelivery) deliver(ctx context.Context, in *PageDeliveryInput) (*PageDeliveryOutput, error) {
+	preview := in.PreviewToken != ""
+	if preview {
+		if h.verifier == nil {
+			return nil, huma.Error401Unauthorized("preview is not enabled")
+		}
+		if err := h.verifier.Verify(ctx, in.PreviewToken); err != nil {
+			return nil, huma.Error401Unauthorized(err.Error())
+		}
+	}
+
+	result, err := h.delivery.Deliver(ctx, pages.PageDeliveryRequest{
+		Scheme:         in.Scheme,
+		Host:           in.Host,
+		Path:           in.Path,
+		AcceptLanguage: in.AcceptLanguage,
+		Preview:        preview,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body := NewPageDeliveryBody(result)
+
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	cacheControl := "public, no-cache"
+	if preview {
+		// the drafts must not be stored by the shared caches
+		cacheControl = "private, no-store"
+	} else if etagMatch(in.IfNoneMatch, etag) {
+		return nil, huma.Status304NotModified()
+	}
+
+	return &PageDeliveryOutput{ETag: etag, CacheControl: cacheControl, Body: body}, nil
+}
+
+func etagMatch(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	return slices.ContainsFunc(strings.Split(ifNoneMatch, ","), func(tag string) bool {
+		return strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag
+	})
+}
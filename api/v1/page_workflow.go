@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/cr"
+	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type ReviewQueueInput struct {
+	SiteID int64 `query:"siteID" required:"false" doc:"Limit the queue to the site"`
+	api.ListInput
+}
+
+func (in *ReviewQueueInput) criteria() *cr.Criteria {
+	criteria := cr.New(in.Filter, in.Sort).SetOffset((in.Page - 1) * in.Limit).SetSize(in.Limit)
+	conditions := []any{cr.Condition{Column: "state", Operator: cr.OpEqual, Value: model.WorkflowInReview}}
+
+	if in.SiteID != 0 {
+		conditions = append(conditions, cr.Condition{Column: "site_id", Operator: cr.OpEqual, Value: in.SiteID})
+	}
+	if !criteria.Filter.IsEmpty() {
+		conditions = append(conditions, criteria.Filter)
+	}
+	return criteria.SetFilter(cr.Filter{Operator: cr.OpAND, Conditions: conditions})
+}
+
+type PageTransitionListInput struct {
+	ID int64 `path:"id"`
+	api.ListInput
+}
+
+func (in *PageTransitionListInput) criteria() *cr.Criteria {
+	criteria := cr.New(in.Filter, in.Sort).SetOffset((in.Page - 1) * in.Limit).SetSize(in.Limit)
+	cond := cr.Condition{Column: "page_id", Operator: cr.OpEqual, Value: in.ID}
+
+	if criteria.Filter.IsEmpty() {
+		return criteria.SetFilter(cr.Filter{Conditions: []any{cond}})
+	}
+	return criteria.SetFilter(cr.Filter{Operator: cr.OpAND, Conditions: []any{criteria.Filter, cond}})
+}
+
+type PageTransitionInput struct {
+	ID         int64  `path:"id"`
+	Transition string `path:"transition" doc:"Name of the workflow transition"`
+	Body       struct {
+		Comment string `json:"comment,omitempty" yaml:"comment,omitempty" required:"false"`
+	}
+}
+
+type PageWorkflow struct {
+	errorTransformer api.ErrorTransformerFunc
+	workflow         pages.PageWorkflow
+	pageRepo         repository.Page
+	transitionRepo   repository.PageTransition
+	uow              repository.UnitOfWork
+	auditor          pages.Auditor
+	dispatcher       pages.EventDispatcher
+	op               func(options ...api.Option) huma.Operation
+}
+
+func NewPageWorkflow(
+	workflow pages.PageWorkflow,
+	pageRepo repository.Page,
+	transitionRepo repository.PageTransition,
+	uow repository.UnitOfWork,
+	auditor pages.Auditor,
+	dispatcher pages.EventDispatcher,
+	errorTransformer api.ErrorTransformerFunc,
+	options ...api.Option,
+) PageWorkflow {
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+
+	opts := make([]api.Option, 0, len(options)+2)
+	opts = append(opts, options...)
+	opts = append(opts, api.WithPath("/pages"), api.WithAddTags("page"))
+
+	return PageWorkflow{
+		errorTransformer: errorTransformer,
+		workflow:         workflow,
+		pageRepo:         pageRepo,
+		transitionRepo:   transitionRepo,
+		uow:              uow,
+		auditor:          auditor,
+		dispatcher:       dispatcher,
+		op:               api.Operation(opts...),
+	}
+}
+
+func (PageWorkflow) Area() string {
+	return Info.Area
+}
+
+func (PageWorkflow) Version() string {
+	return Info.Version
+}
+
+func (h PageWorkflow) Register(_ *echo.Echo, humaAPI huma.API) {
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.reviewQueue), h.op(api.WithSummary("Get pages waiting for review"), api.WithAddPath("/review-queue")))
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.history), h.op(api.WithSummary("Get page workflow history"), api.WithAddPath("/{id}/transitions")))
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.transitions), h.op(api.WithSummary("Get available page transitions"), api.WithAddPath("/{id}/workflow")))
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.apply), h.op(api.WithPost, api.WithOK, api.WithSummary("Apply page transition"), api.WithAddPath("/{id}/workflow/{transition}")))
+}
+
+func (h PageWorkflow) reviewQueue(ctx context.Context, in *ReviewQueueInput) (*api.Response[api.ListOutput[model.Page]], error) {
+	items, total, err := h.pageRepo.FindAndCount(ctx, in.criteria())
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[api.ListOutput[model.Page]]{
+		Body: api.ListOutput[model.Page]{
+			ListInput: in.ListInput,
+			Items:     items,
+			Total:     total,
+		},
+	}, nil
+}
+
+func (h PageWorkflow) history(ctx context.Context, in *PageTransitionListInput) (*api.Response[api.ListOutput[model.PageTransition]], error) {
+	items, total, err := h.transitionRepo.FindAndCount(ctx, in.criteria())
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[api.ListOutput[model.PageTransition]]{
+		Body: api.ListOutput[model.PageTransition]{
+			ListInput: in.ListInput,
+			Items:     items,
+			Total:     total,
+		},
+	}, nil
+}
+
+func (h PageWorkflow) transitions(ctx context.Context, in *api.IDInput[int64]) (*api.Response[[]model.WorkflowTransition], error) {
+	transitions, err := h.workflow.Transitions(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[[]model.WorkflowTransition]{Body: transitions}, nil
+}
+
+func (h PageWorkflow) apply(ctx context.Context, in *PageTransitionInput) (*api.Response[model.Page], error) {
+	var m model.Page
+	err := h.uow.Do(ctx, func(ctx context.Context) (err error) {
+		if m, err = h.workflow.Apply(ctx, in.ID, in.Transition, in.Body.Comment); err != nil {
+			return workflowError(err)
+		}
+
+		switch m.State {
+		case model.WorkflowPublished:
+			return pages.Emit(ctx, h.auditor, h.dispatcher, nil, pages.NewEntityEvent(ctx, pages.EventEntityPage, pages.EventActionPublished, m.ID, &m.SiteID, nil, &m))
+		case model.WorkflowArchived:
+			return pages.Emit(ctx, h.auditor, h.dispatcher, nil, pages.NewEntityEvent(ctx, pages.EventEntityPage, pages.EventActionExpired, m.ID, &m.SiteID, nil, &m))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[model.Page]{Body: m}, nil
+}
+
+func workflowError(err error) error {
+	switch {
+	case errors.Is(err, pages.ErrTransitionForbidden):
+		return huma.Error403Forbidden(err.Error())
+	case errors.Is(err, pages.ErrTransitionNotAllowed):
+		return huma.Error409Conflict(err.Error(), &huma.ErrorDetail{
+			Location: "path.transition",
+			Message:  err.Error(),
+		})
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type PublishBody struct {
+	WithTemplate bool       `json:"withTemplate,omitempty" yaml:"withTemplate,omitempty" required:"false" doc:"Freeze the template of the page with the snapshot"`
+	At           *time.Time `json:"at,omitempty" yaml:"at,omitempty" required:"false" doc:"Publication time of the page, now by default"`
+	Comment      string     `json:"comment,omitempty" yaml:"comment,omitempty" required:"false" doc:"Comment of the publish transition"`
+}
+
+type PublishInput struct {
+	ID   int64 `path:"id"`
+	Body PublishBody
+}
+
+type UnpublishInput struct {
+	ID      int64  `path:"id"`
+	Comment string `query:"comment" required:"false" doc:"Comment of the archive transition"`
+}
+
+// Publish publishes the pages through the publish transition of the workflow,
+// so only the approved pages are published by the actors granted the transition.
+// The pages are unpublished through the archive transition.
+// The changes are audited in their units of work.
+type Publish struct {
+	errorTransformer api.ErrorTransformerFunc
+	workflow         pages.PageWorkflow
+	snapshotRepo     repository.PageSnapshot
+	uow              repository.UnitOfWork
+	auditor          pages.Auditor
+	dispatcher       pages.EventDispatcher
+	op               func(options ...api.Option) huma.Operation
+}
+
+func NewPublish(
+	workflow pages.PageWorkflow,
+	snapshotRepo repository.PageSnapshot,
+	uow repository.UnitOfWork,
+	auditor pages.Auditor,
+	dispatcher pages.EventDispatcher,
+	errorTransformer api.ErrorTransformerFunc,
+	options ...api.Option,
+) Publish {
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+	return Publish{
+		errorTransformer: errorTransformer,
+		workflow:         workflow,
+		snapshotRepo:     snapshotRepo,
+		uow:              uow,
+		auditor:          auditor,
+		dispatcher:       dispatcher,
+		op:               api.Operation(options...),
+	}
+}
+
+func (Publish) Area() string {
+	return Info.Area
+}
+
+func (Publish) Version() string {
+	return Info.Version
+}
+
+func (h Publish) Register(_ *echo.Echo, humaAPI huma.API) {
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.snapshot), h.op(
+		api.WithPath("/pages/{id}/publish"),
+		api.WithAddTags("page"),
+		api.WithSummary("Get published snapshot of page"),
+	))
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.publishPage), h.op(
+		api.WithPost,
+		api.WithOK,
+		api.WithPath("/pages/{id}/publish"),
+		api.WithAddTags("page"),
+		api.WithSummary("Publish page"),
+	))
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.unpublish), h.op(
+		api.WithDelete,
+		api.WithNoContent,
+		api.WithPath("/pages/{id}/publish"),
+		api.WithAddTags("page"),
+		api.WithSummary("Unpublish page"),
+	))
+	api.Register(humaAPI, api.Transform(h.errorTransformer, h.publishSite), h.op(
+		api.WithPost,
+		api.WithOK,
+		api.WithPath("/sites/{id}/publish"),
+		api.WithAddTags("site"),
+		api.WithSummary("Publish all approved pages of site"),
+	))
+}
+
+func (h Publish) snapshot(ctx context.Context, in *api.IDInput[int64]) (*api.Response[model.PageSnapshot], error) {
+	snapshot, err := h.snapshotRepo.FindByID(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[model.PageSnapshot]{Body: snapshot}, nil
+}
+
+func (h Publish) publishPage(ctx context.Context, in *PublishInput) (*api.Response[model.PageSnapshot], error) {
+	var snapshot model.PageSnapshot
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		m, err := h.workflow.Publish(ctx, in.ID, in.Body.WithTemplate, in.Body.At, in.Body.Comment)
+		if err != nil {
+			return workflowError(err)
+		}
+
+		if snapshot, err = h.snapshotRepo.FindByID(ctx, m.ID); err != nil {
+			return err
+		}
+		return pages.Emit(ctx, h.auditor, h.dispatcher, nil, snapshotEvent(ctx, pages.EventActionPublished, snapshot))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[model.PageSnapshot]{Body: snapshot}, nil
+}
+
+func (h Publish) unpublish(ctx context.Context, in *UnpublishInput) (*struct{}, error) {
+	return nil, h.uow.Do(ctx, func(ctx context.Context) error {
+		snapshot, err := h.snapshotRepo.FindByID(ctx, in.ID)
+		if err != nil {
+			return err
+		}
+
+		if _, err = h.workflow.Unpublish(ctx, in.ID, in.Comment); err != nil {
+			return workflowError(err)
+		}
+		return pages.Emit(ctx, h.auditor, h.dispatcher, nil, snapshotEvent(ctx, pages.EventActionExpired, snapshot))
+	})
+}
+
+func (h Publish) publishSite(ctx context.Context, in *PublishInput) (*api.Response[[]model.PageSnapshot], error) {
+	var snapshots []model.PageSnapshot
+	err := h.uow.Do(ctx, func(ctx context.Context) error {
+		data, err := h.workflow.PublishSite(ctx, in.ID, in.Body.WithTemplate, in.Body.At, in.Body.Comment)
+		if err != nil {
+			return workflowError(err)
+		}
+
+		snapshots = make([]model.PageSnapshot, 0, len(data))
+		events := make([]pages.Event, 0, len(data))
+		for _, m := range data {
+			snapshot, err := h.snapshotRepo.FindByID(ctx, m.ID)
+			if err != nil {
+				return err
+			}
+			snapshots = append(snapshots, snapshot)
+			events = append(events, snapshotEvent(ctx, pages.EventActionPublished, snapshot))
+		}
+		return pages.Emit(ctx, h.auditor, h.dispatcher, nil, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[[]model.PageSnapshot]{Body: snapshots}, nil
+}
+
+func snapshotEvent(ctx context.Context, action string, snapshot model.PageSnapshot) pages.Event {
+	page := snapshot.ServedPage()
+	return pages.NewEntityEvent(ctx, pages.EventEntityPage, action, page.ID, &page.SiteID, nil, &page)
+}
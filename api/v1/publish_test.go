@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type testAuditor struct {
+	events []pages.Event
+	err    error
+}
+
+func (a *testAuditor) Audit(ctx context.Context, event pages.Event) error {
+	if !memory.InTx(ctx) {
+		return errors.New("audited outside of the unit of work")
+	}
+	a.events = append(a.events, event)
+	return a.err
+}
+
+func TestUnpublishAuditsInUnitOfWork(t *testing.T) {
+	ctx := pages.WithRoles(pages.WithActor(context.Background(), "carol"), model.RolePublisher)
+	pageRepo := memory.NewPageRepository(pages.ErrPageNotFound)
+	snapshotRepo := memory.NewPageSnapshotRepository(pages.ErrPageSnapshotNotFound)
+	transitionRepo := memory.NewRepository[model.PageTransition](sql.ErrNoRows)
+
+	page := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS, State: model.WorkflowPublished}
+	if err := pageRepo.Create(ctx, &page); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := model.PageSnapshot{ID: page.ID, SiteID: 1, Page: page}
+	if err := snapshotRepo.Create(ctx, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	uow := &memory.UnitOfWork{}
+	auditor := &testAuditor{err: errors.New("audit log is down")}
+	publisher := pages.NewDefaultPagePublisher(pageRepo, memory.NewTemplateRepository(sql.ErrNoRows), snapshotRepo, uow, nil)
+	workflow := pages.NewDefaultPageWorkflow(
+		pageRepo,
+		transitionRepo,
+		memory.NewRepository[model.PageRevision](pages.ErrPageRevisionNotFound),
+		memory.NewConfigurationRepository(model.NewConfiguration()),
+		uow,
+		publisher,
+		nil,
+	)
+	h := NewPublish(workflow, snapshotRepo, repository.NewHookedUnitOfWork(uow), auditor, nil, nil)
+
+	// the role of the archive transition is required
+	if _, err := h.unpublish(context.Background(), &UnpublishInput{ID: page.ID}); err == nil || uow.RolledBack() != 1 {
+		t.Fatalf("err = %v, want the forbidden unpublish rolled back", err)
+	}
+
+	if _, err := h.unpublish(ctx, &UnpublishInput{ID: page.ID}); !errors.Is(err, auditor.err) {
+		t.Fatalf("err = %v, want %v", err, auditor.err)
+	}
+	if uow.RolledBack() != 2 {
+		t.Errorf("rolled back = %d, want the unpublish rolled back with its audit entry", uow.RolledBack())
+	}
+
+	// the memory repositories keep the changes of the rolled back units
+	if err := pageRepo.Update(ctx, &page); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotRepo.Create(ctx, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	auditor.err = nil
+	if _, err := h.unpublish(ctx, &UnpublishInput{ID: page.ID, Comment: "outdated"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(auditor.events) != 2 {
+		t.Fatalf("audited = %v, want the expired events", auditor.events)
+	}
+	if meta := auditor.events[1].Meta(); meta.Action != pages.EventActionExpired || meta.ID != page.ID {
+		t.Errorf("meta = %+v, want page %d expired", meta, page.ID)
+	}
+
+	m, err := pageRepo.FindByID(pages.WithEditor(ctx, true), page.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.State != model.WorkflowArchived {
+		t.Errorf("state = %q, want %q", m.State, model.WorkflowArchived)
+	}
+	last, _ := transitionRepo.FindByID(ctx, int64(transitionRepo.Len()))
+	if last.Transition != model.TransitionArchive || last.Comment != "outdated" {
+		t.Errorf("last transition = %v", last)
+	}
+}
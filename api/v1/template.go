@@ -2,9 +2,15 @@ package v1
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/cr"
 	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
 
+	"github.com/gowool/pages"
 	"github.com/gowool/pages/model"
 	"github.com/gowool/pages/repository"
 )
@@ -13,6 +19,7 @@ type TemplateBody struct {
 	Name    string `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
 	Content string `json:"content,omitempty" yaml:"content,omitempty" required:"false"`
 	Enabled bool   `json:"enabled,omitempty" yaml:"enabled,omitempty" required:"false"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty" required:"false" doc:"Message of the revision"`
 }
 
 func (dto TemplateBody) Decode(_ context.Context, m *model.Template) error {
@@ -22,11 +29,81 @@ func (dto TemplateBody) Decode(_ context.Context, m *model.Template) error {
 	return nil
 }
 
+type TemplateValidateBody struct {
+	Name    string `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
+	Content string `json:"content,omitempty" yaml:"content,omitempty" required:"false"`
+}
+
+type TemplateRevisionListInput struct {
+	ID int64 `path:"id"`
+	api.ListInput
+}
+
+func (in *TemplateRevisionListInput) criteria() *cr.Criteria {
+	criteria := cr.New(in.Filter, in.Sort).SetOffset((in.Page - 1) * in.Limit).SetSize(in.Limit)
+	cond := cr.Condition{Column: "template_id", Operator: cr.OpEqual, Value: in.ID}
+
+	if criteria.Filter.IsEmpty() {
+		return criteria.SetFilter(cr.Filter{Conditions: []any{cond}})
+	}
+	return criteria.SetFilter(cr.Filter{Conditions: []any{criteria.Filter, cond}})
+}
+
+type TemplateRevisionDiffInput struct {
+	ID   int64 `path:"id"`
+	From int64 `query:"from" required:"true" doc:"Old revision"`
+	To   int64 `query:"to" required:"true" doc:"New revision"`
+}
+
+type TemplateRevisionDiffBody struct {
+	From int64  `json:"from" yaml:"from" required:"true"`
+	To   int64  `json:"to" yaml:"to" required:"true"`
+	Diff string `json:"diff" yaml:"diff" required:"true" doc:"Unified diff, empty when the contents are equal"`
+}
+
+type TemplateRestoreInput struct {
+	ID         int64 `path:"id"`
+	RevisionID int64 `path:"revisionID"`
+	Body       struct {
+		Message string `json:"message,omitempty" yaml:"message,omitempty" required:"false" doc:"Message of the revision"`
+	}
+}
+
 type Template struct {
 	api.CRUD[TemplateBody, TemplateBody, model.Template, int64]
+	repo                  repository.Template
+	validator             pages.TemplateValidator
+	analyzer              pages.TemplateAnalyzer
+	revisions             pages.TemplateRevisions
+	revisionRepo          repository.TemplateRevision
+	uow                   repository.UnitOfWork
+	auditor               pages.Auditor
+	dispatcher            pages.EventDispatcher
+	validateOperation     huma.Operation
+	dependenciesOperation huma.Operation
+	dependentsOperation   huma.Operation
+	pagesOperation        huma.Operation
+	revisionsOperation    huma.Operation
+	diffOperation         huma.Operation
+	restoreOperation      huma.Operation
 }
 
-func NewTemplate(repo repository.Template, errorTransformer api.ErrorTransformerFunc, options ...api.Option) Template {
+func NewTemplate(
+	repo repository.Template,
+	validator pages.TemplateValidator,
+	analyzer pages.TemplateAnalyzer,
+	revisions pages.TemplateRevisions,
+	revisionRepo repository.TemplateRevision,
+	uow repository.UnitOfWork,
+	auditor pages.Auditor,
+	dispatcher pages.EventDispatcher,
+	errorTransformer api.ErrorTransformerFunc,
+	options ...api.Option,
+) Template {
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+
 	opts := make([]api.Option, 0, len(options)+2)
 	opts = append(opts, options...)
 	opts = append(opts, api.WithPath("/templates"), api.WithAddTags("template"))
@@ -43,5 +120,196 @@ func NewTemplate(repo repository.Template, errorTransformer api.ErrorTransformer
 			Delete:     api.NewDelete(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete template"), api.WithAddPath("/{id}"))),
 			DeleteMany: api.NewDeleteMany(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete templates"))),
 		},
+		repo:                  repo,
+		validator:             validator,
+		analyzer:              analyzer,
+		revisions:             revisions,
+		revisionRepo:          revisionRepo,
+		uow:                   uow,
+		auditor:               auditor,
+		dispatcher:            dispatcher,
+		validateOperation:     op(api.WithPost, api.WithNoContent, api.WithSummary("Validate template"), api.WithAddPath("/validate")),
+		dependenciesOperation: op(api.WithSummary("Get template dependencies"), api.WithAddPath("/{id}/dependencies")),
+		dependentsOperation:   op(api.WithSummary("Get template dependents"), api.WithAddPath("/{id}/dependents")),
+		pagesOperation:        op(api.WithSummary("Get pages using template"), api.WithAddPath("/{id}/pages")),
+		revisionsOperation:    op(api.WithSummary("Get template revisions"), api.WithAddPath("/{id}/revisions")),
+		diffOperation:         op(api.WithSummary("Diff template revisions"), api.WithAddPath("/{id}/revisions/diff")),
+		restoreOperation:      op(api.WithPost, api.WithOK, api.WithSummary("Restore template revision"), api.WithAddPath("/{id}/revisions/{revisionID}/restore")),
+	}
+}
+
+func (h Template) Register(e *echo.Echo, humaAPI huma.API) {
+	h.Create.Saver = h.save(h.repo.Create)
+	h.Update.Saver = h.save(h.repo.Update)
+	h.Delete.Deleter = h.delete
+	h.DeleteMany.Deleter = h.delete
+
+	h.List.Register(e, humaAPI)
+	h.Read.Register(e, humaAPI)
+	h.Delete.Register(e, humaAPI)
+	h.DeleteMany.Register(e, humaAPI)
+	api.Register(humaAPI, api.Transform(h.Create.ErrorTransformer, h.create), h.Create.Operation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.update), h.Update.Operation)
+	api.Register(humaAPI, api.Transform(h.Create.ErrorTransformer, h.validate), h.validateOperation)
+	api.Register(humaAPI, api.Transform(h.Read.ErrorTransformer, h.dependencies), h.dependenciesOperation)
+	api.Register(humaAPI, api.Transform(h.Read.ErrorTransformer, h.dependents), h.dependentsOperation)
+	api.Register(humaAPI, api.Transform(h.Read.ErrorTransformer, h.pages), h.pagesOperation)
+	api.Register(humaAPI, api.Transform(h.List.ErrorTransformer, h.revisionList), h.revisionsOperation)
+	api.Register(humaAPI, api.Transform(h.Read.ErrorTransformer, h.diff), h.diffOperation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.restore), h.restoreOperation)
+}
+
+func (h Template) create(ctx context.Context, in *api.CreateInput[TemplateBody]) (*api.CreateResponse, error) {
+	return h.Create.Handler(withRevisionMessage(ctx, in.Body.Message), in)
+}
+
+func (h Template) update(ctx context.Context, in *api.UpdateInput[TemplateBody, int64]) (*struct{}, error) {
+	return h.Update.Handler(withRevisionMessage(ctx, in.Body.Message), in)
+}
+
+func (h Template) revisionList(ctx context.Context, in *TemplateRevisionListInput) (*api.Response[api.ListOutput[model.TemplateRevision]], error) {
+	items, total, err := h.revisionRepo.FindAndCount(ctx, in.criteria())
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[api.ListOutput[model.TemplateRevision]]{
+		Body: api.ListOutput[model.TemplateRevision]{
+			ListInput: in.ListInput,
+			Items:     items,
+			Total:     total,
+		},
+	}, nil
+}
+
+func (h Template) diff(ctx context.Context, in *TemplateRevisionDiffInput) (*api.Response[TemplateRevisionDiffBody], error) {
+	from, err := h.revisionRepo.FindByID(ctx, in.From)
+	if err != nil {
+		return nil, err
+	}
+	if from.TemplateID != in.ID {
+		return nil, pages.ErrTemplateRevisionNotFound
+	}
+
+	diff, err := h.revisions.Diff(ctx, in.From, in.To)
+	if err != nil {
+		return nil, validationError(err)
+	}
+	return &api.Response[TemplateRevisionDiffBody]{Body: TemplateRevisionDiffBody{From: in.From, To: in.To, Diff: diff}}, nil
+}
+
+func (h Template) restore(ctx context.Context, in *TemplateRestoreInput) (*api.Response[model.Template], error) {
+	revision, err := h.revisionRepo.FindByID(ctx, in.RevisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.TemplateID != in.ID {
+		return nil, pages.ErrTemplateRevisionNotFound
+	}
+
+	var m model.Template
+	err = h.uow.Do(ctx, func(ctx context.Context) error {
+		before, err := h.repo.FindByID(ctx, in.ID)
+		if err != nil {
+			return err
+		}
+
+		if m, err = h.revisions.Restore(ctx, in.RevisionID, in.Body.Message); err != nil {
+			return templateSyntaxError(err)
+		}
+		return pages.Emit(ctx, h.auditor, h.dispatcher, nil, pages.NewEntityEvent(ctx, pages.EventEntityTemplate, pages.EventActionRestored, m.ID, nil, &before, &m))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[model.Template]{Body: m}, nil
+}
+
+func (h Template) dependencies(ctx context.Context, in *api.IDInput[int64]) (*api.Response[[]pages.TemplateReference], error) {
+	m, err := h.repo.FindByID(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := h.analyzer.Dependencies(ctx, m.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[[]pages.TemplateReference]{Body: refs}, nil
+}
+
+func (h Template) dependents(ctx context.Context, in *api.IDInput[int64]) (*api.Response[[]pages.TemplateReference], error) {
+	m, err := h.repo.FindByID(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := h.analyzer.Dependents(ctx, m.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[[]pages.TemplateReference]{Body: refs}, nil
+}
+
+func (h Template) pages(ctx context.Context, in *api.IDInput[int64]) (*api.Response[[]model.Page], error) {
+	m, err := h.repo.FindByID(ctx, in.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := h.analyzer.Pages(ctx, m.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[[]model.Page]{Body: data}, nil
+}
+
+func (h Template) delete(ctx context.Context, ids ...int64) error {
+	if err := h.analyzer.CanDelete(ctx, ids...); err != nil {
+		var inUseErr *pages.TemplateInUseError
+		if errors.As(err, &inUseErr) {
+			details := make([]error, 0, len(inUseErr.References)+len(inUseErr.Pages))
+			for _, ref := range inUseErr.References {
+				details = append(details, &huma.ErrorDetail{
+					Location: "template." + ref.From,
+					Message:  fmt.Sprintf("%s reference at line %d", ref.Kind, ref.Line),
+					Value:    ref.To,
+				})
+			}
+			for _, page := range inUseErr.Pages {
+				details = append(details, &huma.ErrorDetail{
+					Location: fmt.Sprintf("page.%d", page.ID),
+					Message:  "page template",
+					Value:    page.Template,
+				})
+			}
+			return huma.Error409Conflict(inUseErr.Error(), details...)
+		}
+		return err
+	}
+	return h.repo.Delete(ctx, ids...)
+}
+
+func (h Template) validate(ctx context.Context, in *api.CreateInput[TemplateValidateBody]) (*struct{}, error) {
+	return nil, templateSyntaxError(h.validator.Validate(ctx, in.Body.Name, in.Body.Content))
+}
+
+func (h Template) save(saver func(context.Context, *model.Template) error) func(context.Context, *model.Template) error {
+	return func(ctx context.Context, m *model.Template) error {
+		if err := h.validator.Validate(ctx, m.Name, m.Content); err != nil {
+			return templateSyntaxError(err)
+		}
+		return h.revisions.Save(ctx, m, revisionMessage(ctx), saver)
+	}
+}
+
+func templateSyntaxError(err error) error {
+	var syntaxErr *pages.TemplateSyntaxError
+	if errors.As(err, &syntaxErr) {
+		return huma.Error422UnprocessableEntity(syntaxErr.Error(), &huma.ErrorDetail{
+			Location: "body.content",
+			Message:  syntaxErr.Message,
+			Value:    syntaxErr,
+		})
 	}
+	return err
 }
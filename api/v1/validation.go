@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	"github.com/gowool/pages"
+)
+
+func validationError(err error) error {
+	var validationErr *pages.ValidationError
+	if errors.As(err, &validationErr) {
+		details := make([]error, 0, len(validationErr.Errors))
+		for _, item := range validationErr.Errors {
+			details = append(details, &huma.ErrorDetail{
+				Location: "body." + item.Field,
+				Message:  item.Message,
+				Value:    item.Value,
+			})
+		}
+		return huma.Error422UnprocessableEntity(validationErr.Error(), details...)
+	}
+
+	var conflictErr *pages.PageURLConflictError
+	if errors.As(err, &conflictErr) {
+		return huma.Error422UnprocessableEntity(conflictErr.Error(), &huma.ErrorDetail{
+			Location: "body.slug",
+			Message:  conflictErr.Error(),
+			Value:    conflictErr.Page.URL,
+		})
+	}
+	return cycleError(err)
+}
+
+func cycleError(err error) error {
+	if errors.Is(err, pages.ErrPageTreeCycle) || errors.Is(err, pages.ErrNodeTreeCycle) {
+		return huma.Error422UnprocessableEntity(err.Error(), &huma.ErrorDetail{
+			Location: "body.parentID",
+			Message:  err.Error(),
+		})
+	}
+	return err
+}
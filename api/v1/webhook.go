@@ -0,0 +1,184 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/gowool/cr"
+	"github.com/gowool/echox/api"
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type WebhookBody struct {
+	Name     string   `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
+	URL      string   `json:"url,omitempty" yaml:"url,omitempty" required:"true" format:"uri"`
+	Secret   string   `json:"secret,omitempty" yaml:"secret,omitempty" required:"false" doc:"Secret of the signatures, kept on update when empty"`
+	Entities []string `json:"entities,omitempty" yaml:"entities,omitempty" required:"false" enum:"site,page,template,menu,node,configuration"`
+	Actions  []string `json:"actions,omitempty" yaml:"actions,omitempty" required:"false" enum:"created,updated,deleted,published,expired"`
+	SiteIDs  []int64  `json:"siteIDs,omitempty" yaml:"siteIDs,omitempty" required:"false"`
+	Enabled  bool     `json:"enabled,omitempty" yaml:"enabled,omitempty" required:"false"`
+}
+
+func (dto WebhookBody) Decode(_ context.Context, m *model.Webhook) error {
+	m.Name = dto.Name
+	m.URL = dto.URL
+	if dto.Secret != "" {
+		m.Secret = dto.Secret
+	}
+	m.Entities = dto.Entities
+	m.Actions = dto.Actions
+	m.SiteIDs = dto.SiteIDs
+	m.Enabled = dto.Enabled
+	return nil
+}
+
+type WebhookDeliveryListInput struct {
+	ID int64 `path:"id"`
+	api.ListInput
+}
+
+func (in *WebhookDeliveryListInput) criteria() *cr.Criteria {
+	criteria := cr.New(in.Filter, in.Sort).SetOffset((in.Page - 1) * in.Limit).SetSize(in.Limit)
+	cond := cr.Condition{Column: "webhook_id", Operator: cr.OpEqual, Value: in.ID}
+
+	if criteria.Filter.IsEmpty() {
+		return criteria.SetFilter(cr.Filter{Conditions: []any{cond}})
+	}
+	return criteria.SetFilter(cr.Filter{Operator: cr.OpAND, Conditions: []any{criteria.Filter, cond}})
+}
+
+type WebhookDeliveryInput struct {
+	ID         int64 `path:"id"`
+	DeliveryID int64 `path:"deliveryID"`
+}
+
+type Webhook struct {
+	api.CRUD[WebhookBody, WebhookBody, model.Webhook, int64]
+	webhooks            pages.Webhooks
+	deliveryRepo        repository.WebhookDelivery
+	uow                 repository.UnitOfWork
+	auditor             pages.Auditor
+	dispatcher          pages.EventDispatcher
+	deliveriesOperation huma.Operation
+	deliveryOperation   huma.Operation
+	replayOperation     huma.Operation
+}
+
+func NewWebhook(
+	repo repository.Webhook,
+	deliveryRepo repository.WebhookDelivery,
+	webhooks pages.Webhooks,
+	uow repository.UnitOfWork,
+	auditor pages.Auditor,
+	dispatcher pages.EventDispatcher,
+	errorTransformer api.ErrorTransformerFunc,
+	options ...api.Option,
+) Webhook {
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+
+	opts := make([]api.Option, 0, len(options)+2)
+	opts = append(opts, options...)
+	opts = append(opts, api.WithPath("/webhooks"), api.WithAddTags("webhook"))
+
+	op := api.Operation(opts...)
+
+	// the secrets are write only
+	findAndCount := func(ctx context.Context, criteria *cr.Criteria) ([]model.Webhook, int, error) {
+		items, total, err := repo.FindAndCount(ctx, criteria)
+		return internal.Map(items, redactWebhook), total, err
+	}
+	findByID := func(ctx context.Context, id int64) (model.Webhook, error) {
+		m, err := repo.FindByID(ctx, id)
+		return redactWebhook(m), err
+	}
+
+	return Webhook{
+		CRUD: api.CRUD[WebhookBody, WebhookBody, model.Webhook, int64]{
+			Info:       Info,
+			List:       api.NewList(findAndCount, errorTransformer, op(api.WithSummary("Get webhooks"))),
+			Read:       api.NewRead(findByID, errorTransformer, op(api.WithSummary("Get webhook"), api.WithAddPath("/{id}"))),
+			Create:     api.NewCreate[WebhookBody](repo.Create, errorTransformer, op(api.WithPost, api.WithSummary("Create webhook"))),
+			Update:     api.NewUpdate[WebhookBody](repo.FindByID, repo.Update, errorTransformer, op(api.WithPut, api.WithSummary("Update webhook"), api.WithAddPath("/{id}"))),
+			Delete:     api.NewDelete(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete webhook"), api.WithAddPath("/{id}"))),
+			DeleteMany: api.NewDeleteMany(repo.Delete, errorTransformer, op(api.WithDelete, api.WithSummary("Delete webhooks"))),
+		},
+		webhooks:            webhooks,
+		deliveryRepo:        deliveryRepo,
+		uow:                 uow,
+		auditor:             auditor,
+		dispatcher:          dispatcher,
+		deliveriesOperation: op(api.WithSummary("Get webhook deliveries"), api.WithAddPath("/{id}/deliveries")),
+		deliveryOperation:   op(api.WithSummary("Get webhook delivery"), api.WithAddPath("/{id}/deliveries/{deliveryID}")),
+		replayOperation:     op(api.WithPost, api.WithOK, api.WithSummary("Replay webhook delivery"), api.WithAddPath("/{id}/deliveries/{deliveryID}/replay")),
+	}
+}
+
+func (h Webhook) Register(e *echo.Echo, humaAPI huma.API) {
+	h.CRUD.Register(e, humaAPI)
+	api.Register(humaAPI, api.Transform(h.List.ErrorTransformer, h.deliveries), h.deliveriesOperation)
+	api.Register(humaAPI, api.Transform(h.Read.ErrorTransformer, h.delivery), h.deliveryOperation)
+	api.Register(humaAPI, api.Transform(h.Update.ErrorTransformer, h.replay), h.replayOperation)
+}
+
+func (h Webhook) deliveries(ctx context.Context, in *WebhookDeliveryListInput) (*api.Response[api.ListOutput[model.WebhookDelivery]], error) {
+	items, total, err := h.deliveryRepo.FindAndCount(ctx, in.criteria())
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[api.ListOutput[model.WebhookDelivery]]{
+		Body: api.ListOutput[model.WebhookDelivery]{
+			ListInput: in.ListInput,
+			Items:     items,
+			Total:     total,
+		},
+	}, nil
+}
+
+func (h Webhook) delivery(ctx context.Context, in *WebhookDeliveryInput) (*api.Response[model.WebhookDelivery], error) {
+	m, err := h.find(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[model.WebhookDelivery]{Body: m}, nil
+}
+
+func (h Webhook) replay(ctx context.Context, in *WebhookDeliveryInput) (*api.Response[model.WebhookDelivery], error) {
+	if _, err := h.find(ctx, in); err != nil {
+		return nil, err
+	}
+
+	var m model.WebhookDelivery
+	err := h.uow.Do(ctx, func(ctx context.Context) (err error) {
+		if m, err = h.webhooks.Replay(ctx, in.DeliveryID); err != nil {
+			return err
+		}
+		return pages.Emit(ctx, h.auditor, h.dispatcher, nil, pages.NewEntityEvent(ctx, pages.EventEntityWebhookDelivery, pages.EventActionReplayed, m.ID, nil, nil, &m))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &api.Response[model.WebhookDelivery]{Body: m}, nil
+}
+
+func (h Webhook) find(ctx context.Context, in *WebhookDeliveryInput) (model.WebhookDelivery, error) {
+	m, err := h.deliveryRepo.FindByID(ctx, in.DeliveryID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+	if m.WebhookID != in.ID {
+		return model.WebhookDelivery{}, pages.ErrWebhookDeliveryNotFound
+	}
+	return m, nil
+}
+
+func redactWebhook(m model.Webhook) model.Webhook {
+	m.Secret = ""
+	return m
+}
@@ -0,0 +1,53 @@
+package pages
+
+import (
+	"context"
+	"time"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ Auditor = (*DefaultAuditor)(nil)
+
+// Auditor records the events in the audit log. It is called in the unit of work of the change,
+// so the entry is saved together with the change or not at all.
+type Auditor interface {
+	Audit(ctx context.Context, event Event) error
+}
+
+// DefaultAuditor records the events of the changes made by the actors in the audit log.
+type DefaultAuditor struct {
+	auditRepo repository.AuditLog
+}
+
+func NewDefaultAuditor(auditRepo repository.AuditLog) *DefaultAuditor {
+	if auditRepo == nil {
+		panic("audit log repository is not specified")
+	}
+	return &DefaultAuditor{auditRepo: auditRepo}
+}
+
+func (a *DefaultAuditor) Audit(ctx context.Context, event Event) error {
+	meta := event.Meta()
+
+	changes, err := FieldDiff(event.States())
+	if err != nil {
+		return err
+	}
+
+	created := meta.Occurred
+	if created.IsZero() {
+		created = time.Now().UTC()
+	}
+
+	return a.auditRepo.Create(ctx, &model.AuditEntry{
+		Actor:    meta.Actor,
+		Entity:   meta.Entity,
+		EntityID: meta.ID,
+		SiteID:   meta.SiteID,
+		Action:   meta.Action,
+		Changes:  changes,
+		Created:  created,
+	})
+}
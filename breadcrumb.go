@@ -0,0 +1,211 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type BreadcrumbItem struct {
+	Label string      `json:"label,omitempty" yaml:"label,omitempty"`
+	URL   string      `json:"url,omitempty" yaml:"url,omitempty"`
+	Page  *model.Page `json:"-" yaml:"-"`
+	Node  *model.Node `json:"-" yaml:"-"`
+}
+
+// Breadcrumbs is the trail of the current request.
+// It is shared through the request context, so controllers and templates append to the same trail.
+type Breadcrumbs struct {
+	mu    sync.RWMutex
+	items []BreadcrumbItem
+}
+
+func NewBreadcrumbs(items ...BreadcrumbItem) *Breadcrumbs {
+	return &Breadcrumbs{items: items}
+}
+
+func (b *Breadcrumbs) Items() []BreadcrumbItem {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return slices.Clone(b.items)
+}
+
+func (b *Breadcrumbs) Len() int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.items)
+}
+
+func (b *Breadcrumbs) Add(label, url string) *Breadcrumbs {
+	return b.Append(BreadcrumbItem{Label: label, URL: url})
+}
+
+func (b *Breadcrumbs) Append(items ...BreadcrumbItem) *Breadcrumbs {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.items = append(b.items, items...)
+	return b
+}
+
+func (b *Breadcrumbs) Prepend(items ...BreadcrumbItem) *Breadcrumbs {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.items = append(slices.Clone(items), b.items...)
+	return b
+}
+
+func (b *Breadcrumbs) Reset() *Breadcrumbs {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.items = nil
+	return b
+}
+
+var _ BreadcrumbBuilder = (*DefaultBreadcrumbBuilder)(nil)
+
+type BreadcrumbBuilder interface {
+	// FromPage returns the trail from the site root to the page following the page parents.
+	FromPage(ctx context.Context, page *model.Page) ([]BreadcrumbItem, error)
+
+	// FromMenu returns the trail from the menu root to its current node.
+	FromMenu(ctx context.Context, handle string) ([]BreadcrumbItem, error)
+}
+
+type DefaultBreadcrumbBuilder struct {
+	pageRepo repository.Page
+	menu     Menu
+	matcher  Matcher
+}
+
+func NewDefaultBreadcrumbBuilder(pageRepo repository.Page, menu Menu, matcher Matcher) *DefaultBreadcrumbBuilder {
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	if menu == nil {
+		panic("menu is not specified")
+	}
+	if matcher == nil {
+		panic("matcher is not specified")
+	}
+	return &DefaultBreadcrumbBuilder{
+		pageRepo: pageRepo,
+		menu:     menu,
+		matcher:  matcher,
+	}
+}
+
+func (b *DefaultBreadcrumbBuilder) FromPage(ctx context.Context, page *model.Page) ([]BreadcrumbItem, error) {
+	if page == nil || page.ID <= 0 {
+		return nil, nil
+	}
+
+	site := page.Site
+	if site == nil {
+		site = CtxSite(ctx)
+	}
+
+	// the trail stops at the first ancestor hidden from the visitors
+	var now time.Time
+	if !CtxEditor(ctx) {
+		now = time.Now().UTC()
+	}
+
+	var items []BreadcrumbItem
+	visited := map[int64]struct{}{}
+
+	for current := page; current != nil; {
+		if _, ok := visited[current.ID]; ok {
+			return nil, fmt.Errorf("breadcrumbs: page %d is visited twice: %w", current.ID, ErrPageTreeCycle)
+		}
+		visited[current.ID] = struct{}{}
+
+		if !current.IsInternal() {
+			items = append(items, pageBreadcrumb(site, current))
+		}
+
+		if current.ParentID == nil {
+			break
+		}
+
+		parent := current.Parent
+		if parent == nil {
+			m, err := b.pageRepo.FindByID(ctx, *current.ParentID)
+			if err != nil {
+				if IsOneOfNotFound(err) {
+					break
+				}
+				return nil, err
+			}
+			parent = &m
+		}
+
+		if !now.IsZero() && !parent.IsEnabled(now) {
+			break
+		}
+		current = parent
+	}
+
+	slices.Reverse(items)
+	return items, nil
+}
+
+func (b *DefaultBreadcrumbBuilder) FromMenu(ctx context.Context, handle string) ([]BreadcrumbItem, error) {
+	menu, err := b.menu.Get(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+	if menu.Node == nil {
+		return nil, nil
+	}
+
+	path := CurrentNodePath(ctx, b.matcher, menu.Node)
+	if path == nil {
+		return nil, nil
+	}
+
+	items := make([]BreadcrumbItem, 0, len(path))
+	for _, node := range path {
+		// the root node is a container of the menu items unless it has a link
+		if node == menu.Node && node.URI == "" {
+			continue
+		}
+
+		label := node.Label
+		if label == "" {
+			label = node.Name
+		}
+		items = append(items, BreadcrumbItem{Label: label, URL: node.URI, Page: node.Page, Node: node})
+	}
+	return items, nil
+}
+
+func pageBreadcrumb(site *model.Site, page *model.Page) BreadcrumbItem {
+	label := page.Title
+	if label == "" {
+		label = page.Name
+	}
+
+	var url string
+	if page.URL != "" && !page.IsDynamic() {
+		url = PageURI(site, *page)
+	}
+	return BreadcrumbItem{Label: label, URL: url, Page: page}
+}
@@ -0,0 +1,46 @@
+package pages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func TestBreadcrumbBuilderFromPageStopsAtHiddenAncestor(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewPageRepository(ErrPageNotFound)
+	tree := NewDefaultPageTree(repo, &memory.UnitOfWork{}, nil)
+
+	published := internal.Ptr(time.Now().Add(-time.Hour))
+	root := createTestPage(t, tree, model.Page{Name: "Home", Published: published})
+	hidden := createTestPage(t, tree, model.Page{Name: "Hidden", ParentID: &root.ID})
+	blog := createTestPage(t, tree, model.Page{Name: "Blog", ParentID: &hidden.ID, Published: published})
+	post := createTestPage(t, tree, model.Page{Name: "Post", ParentID: &blog.ID, Published: published})
+
+	matcher := NewDefaultMatcher()
+	builder := NewDefaultBreadcrumbBuilder(repo, NewDefaultMenu(memory.NewMenuRepository(ErrMenuNotFound), memory.NewNodeRepository(ErrNodeNotFound), repo, matcher), matcher)
+
+	labels := func(items []BreadcrumbItem) []string {
+		return internal.Map(items, func(item BreadcrumbItem) string { return item.Label })
+	}
+
+	items, err := builder.FromPage(ctx, &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(items); len(got) != 2 || got[0] != "Blog" || got[1] != "Post" {
+		t.Errorf("visitor trail = %v, want [Blog Post]", got)
+	}
+
+	items, err = builder.FromPage(WithEditor(ctx, true), &post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(items); len(got) != 4 {
+		t.Errorf("editor trail = %v, want all the ancestors", got)
+	}
+}
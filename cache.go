@@ -0,0 +1,21 @@
+package pages
+
+import "fmt"
+
+const (
+	CachePrefixConfiguration = "cms::page:configuration"
+	CachePrefixSite          = "cms::site"
+	CachePrefixPage          = "cms::page"
+	CachePrefixTemplate      = "cms::template"
+	CachePrefixMenu          = "cms::menu"
+	CachePrefixNode          = "cms::node"
+)
+
+func CacheTag(prefix string, id any) string {
+	return fmt.Sprintf("%s:tag:%v", prefix, id)
+}
+
+// SiteHostsCacheTag is purged when the set of the enabled sites changes.
+func SiteHostsCacheTag() string {
+	return CacheTag(CachePrefixSite, "hosts")
+}
@@ -18,6 +18,10 @@ type (
 	editorKey         struct{}
 	dataKey           struct{}
 	urlKey            struct{}
+	breadcrumbsKey    struct{}
+	routePatternKey   struct{}
+	actorKey          struct{}
+	rolesKey          struct{}
 )
 
 func WithDebug(ctx context.Context, debug bool) context.Context {
@@ -102,3 +106,41 @@ func CtxURL(ctx context.Context) url.URL {
 	u, _ := ctx.Value(urlKey{}).(url.URL)
 	return u
 }
+
+func WithRoutePattern(ctx context.Context, pattern string) context.Context {
+	return context.WithValue(ctx, routePatternKey{}, pattern)
+}
+
+func CtxRoutePattern(ctx context.Context) string {
+	pattern, _ := ctx.Value(routePatternKey{}).(string)
+	return pattern
+}
+
+func WithBreadcrumbs(ctx context.Context, b *Breadcrumbs) context.Context {
+	return context.WithValue(ctx, breadcrumbsKey{}, b)
+}
+
+func CtxBreadcrumbs(ctx context.Context) *Breadcrumbs {
+	b, _ := ctx.Value(breadcrumbsKey{}).(*Breadcrumbs)
+	return b
+}
+
+// WithActor sets the author of the changes made by the request.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func CtxActor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithRoles sets the workflow roles of the actor.
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesKey{}, roles)
+}
+
+func CtxRoles(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey{}).([]string)
+	return roles
+}
@@ -3,15 +3,72 @@ package pages
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
 )
 
 var (
-	ErrInternal     = errors.New("internal server error")
-	ErrSiteNotFound = errors.New("site not found")
-	ErrPageNotFound = errors.New("page not found")
-	ErrMenuNotFound = errors.New("menu not found")
+	ErrInternal        = errors.New("internal server error")
+	ErrSiteNotFound    = errors.New("site not found")
+	ErrPageNotFound    = errors.New("page not found")
+	ErrMenuNotFound    = errors.New("menu not found")
+	ErrNodeNotFound    = errors.New("node not found")
+	ErrPageTreeCycle   = errors.New("page tree cycle")
+	ErrNodeTreeCycle   = errors.New("node tree cycle")
+	ErrPageURLConflict = errors.New("page url conflict")
+	ErrValidation      = errors.New("validation failed")
+	ErrTemplateSyntax  = errors.New("template syntax error")
+	ErrTemplateInUse   = errors.New("template in use")
+
+	ErrInvalidPreviewToken      = errors.New("invalid preview token")
+	ErrTemplateRevisionNotFound = errors.New("template revision not found")
+	ErrPageRevisionNotFound     = errors.New("page revision not found")
+	ErrPageSnapshotNotFound     = errors.New("page snapshot not found")
+	ErrWebhookDeliveryNotFound  = errors.New("webhook delivery not found")
+	ErrTransitionNotAllowed     = errors.New("workflow transition not allowed")
+	ErrTransitionForbidden      = errors.New("workflow transition forbidden")
 )
 
+type FieldError struct {
+	Field   string
+	Value   any
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(internal.Map(e.Errors, func(item FieldError) string {
+		return item.Error()
+	}), "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+type PageURLConflictError struct {
+	Page       model.Page
+	ConflictID int64
+}
+
+func (e *PageURLConflictError) Error() string {
+	return fmt.Sprintf("page tree: url %q of page %d conflicts with page %d", e.Page.URL, e.Page.ID, e.ConflictID)
+}
+
+func (e *PageURLConflictError) Unwrap() error {
+	return ErrPageURLConflict
+}
+
 func IsOneOfNotFound(err error) bool {
-	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrSiteNotFound) || errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrMenuNotFound)
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrSiteNotFound) || errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrMenuNotFound) || errors.Is(err, ErrNodeNotFound) || errors.Is(err, ErrTemplateRevisionNotFound) || errors.Is(err, ErrPageRevisionNotFound) || errors.Is(err, ErrPageSnapshotNotFound) || errors.Is(err, ErrWebhookDeliveryNotFound)
 }
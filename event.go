@@ -0,0 +1,327 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+const (
+	EventEntitySite            = "site"
+	EventEntityPage            = "page"
+	EventEntityTemplate        = "template"
+	EventEntityMenu            = "menu"
+	EventEntityNode            = "node"
+	EventEntityConfiguration   = "configuration"
+	EventEntityWebhook         = "webhook"
+	EventEntityWebhookDelivery = "webhook_delivery"
+
+	EventActionCreated   = "created"
+	EventActionUpdated   = "updated"
+	EventActionDeleted   = "deleted"
+	EventActionPublished = "published"
+	EventActionExpired   = "expired"
+	EventActionRestored  = "restored"
+	EventActionReplayed  = "replayed"
+)
+
+var (
+	_ EventDispatcher  = (*DefaultEventDispatcher)(nil)
+	_ EventDispatcher  = (*AsyncEventDispatcher)(nil)
+	_ ScheduleListener = ScheduleEventListener{}
+)
+
+type EventMeta struct {
+	Entity   string    `json:"entity" yaml:"entity"`
+	Action   string    `json:"action" yaml:"action"`
+	ID       int64     `json:"id,omitempty" yaml:"id,omitempty"`
+	SiteID   *int64    `json:"siteID,omitempty" yaml:"siteID,omitempty"`
+	Actor    string    `json:"actor,omitempty" yaml:"actor,omitempty"`
+	Occurred time.Time `json:"occurred" yaml:"occurred"`
+}
+
+type Event interface {
+	Meta() EventMeta
+
+	// States returns the models before and after the change, nil when missing.
+	States() (before, after any)
+}
+
+// EntityEvent carries the states of the entity before and after the change.
+// After is nil for the deleted entities only, Before is set for the updated and deleted ones.
+type EntityEvent[M any] struct {
+	EventMeta
+	Before *M `json:"before,omitempty" yaml:"before,omitempty"`
+	After  *M `json:"after,omitempty" yaml:"after,omitempty"`
+}
+
+func (e EntityEvent[M]) Meta() EventMeta {
+	return e.EventMeta
+}
+
+func (e EntityEvent[M]) States() (before, after any) {
+	if e.Before != nil {
+		before = e.Before
+	}
+	if e.After != nil {
+		after = e.After
+	}
+	return
+}
+
+type (
+	SiteEvent            = EntityEvent[model.Site]
+	PageEvent            = EntityEvent[model.Page]
+	TemplateEvent        = EntityEvent[model.Template]
+	MenuEvent            = EntityEvent[model.Menu]
+	NodeEvent            = EntityEvent[model.Node]
+	ConfigurationEvent   = EntityEvent[model.Configuration]
+	WebhookEvent         = EntityEvent[model.Webhook]
+	WebhookDeliveryEvent = EntityEvent[model.WebhookDelivery]
+)
+
+// NewEntityEvent returns the event of the change made by the context actor.
+func NewEntityEvent[M any](ctx context.Context, entity, action string, id int64, siteID *int64, before, after *M) EntityEvent[M] {
+	return EntityEvent[M]{
+		EventMeta: EventMeta{
+			Entity:   entity,
+			Action:   action,
+			ID:       id,
+			SiteID:   siteID,
+			Actor:    CtxActor(ctx),
+			Occurred: time.Now().UTC(),
+		},
+		Before: before,
+		After:  after,
+	}
+}
+
+// Emit records the events in the audit log in the unit of work of the context and dispatches them
+// once the unit of work is committed. The audit errors fail the change, the dispatch errors are logged
+// as the change is saved already. The audit is disabled without an auditor, the events without a dispatcher.
+func Emit(ctx context.Context, auditor Auditor, dispatcher EventDispatcher, logger *zap.Logger, events ...Event) error {
+	if auditor != nil {
+		for _, event := range events {
+			if err := auditor.Audit(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dispatcher == nil || len(events) == 0 {
+		return nil
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	repository.AfterCommit(ctx, func(ctx context.Context) {
+		for _, event := range events {
+			if err := dispatcher.Dispatch(ctx, event); err != nil {
+				meta := event.Meta()
+				logger.Error("event: dispatch failed",
+					zap.String("entity", meta.Entity),
+					zap.String("action", meta.Action),
+					zap.Int64("id", meta.ID),
+					zap.Error(err))
+			}
+		}
+	})
+	return nil
+}
+
+type EventSubscriber interface {
+	HandleEvent(ctx context.Context, event Event) error
+}
+
+type EventSubscriberFunc func(ctx context.Context, event Event) error
+
+func (f EventSubscriberFunc) HandleEvent(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
+
+// Subscribe returns the subscriber handling the events of the model type only.
+func Subscribe[M any](handler func(ctx context.Context, event EntityEvent[M]) error) EventSubscriber {
+	return EventSubscriberFunc(func(ctx context.Context, event Event) error {
+		if e, ok := event.(EntityEvent[M]); ok {
+			return handler(ctx, e)
+		}
+		return nil
+	})
+}
+
+type EventDispatcher interface {
+	Dispatch(ctx context.Context, events ...Event) error
+}
+
+// DefaultEventDispatcher calls the subscribers one by one in the goroutine of the caller.
+// It calls all the subscribers and returns their joined errors.
+type DefaultEventDispatcher struct {
+	subscribers []EventSubscriber
+	logger      *zap.Logger
+}
+
+func NewDefaultEventDispatcher(subscribers []EventSubscriber, logger *zap.Logger) *DefaultEventDispatcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &DefaultEventDispatcher{
+		subscribers: subscribers,
+		logger:      logger,
+	}
+}
+
+func (d *DefaultEventDispatcher) Dispatch(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, event := range events {
+		for _, subscriber := range d.subscribers {
+			if err := subscriber.HandleEvent(ctx, event); err != nil {
+				meta := event.Meta()
+				d.logger.Error("event: subscriber failed",
+					zap.String("entity", meta.Entity),
+					zap.String("action", meta.Action),
+					zap.Int64("id", meta.ID),
+					zap.Error(err))
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AsyncEventDispatcher queues the events and dispatches them in the background,
+// so Dispatch returns before the subscribers are called. Stop drains the queue.
+type AsyncEventDispatcher struct {
+	dispatcher *DefaultEventDispatcher
+	queue      chan queuedEvent
+	wg         sync.WaitGroup
+	mu         sync.RWMutex
+	closed     bool
+}
+
+type queuedEvent struct {
+	ctx   context.Context
+	event Event
+}
+
+func NewAsyncEventDispatcher(subscribers []EventSubscriber, logger *zap.Logger) *AsyncEventDispatcher {
+	d := &AsyncEventDispatcher{
+		dispatcher: NewDefaultEventDispatcher(subscribers, logger),
+		queue:      make(chan queuedEvent, 1024),
+	}
+
+	d.wg.Add(1)
+	go d.run()
+	return d
+}
+
+func (d *AsyncEventDispatcher) Dispatch(ctx context.Context, events ...Event) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return errors.New("event: async dispatcher is stopped")
+	}
+
+	// the request may be over before the event is handled
+	ctx = context.WithoutCancel(ctx)
+	for _, event := range events {
+		d.queue <- queuedEvent{ctx: ctx, event: event}
+	}
+	return nil
+}
+
+func (d *AsyncEventDispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *AsyncEventDispatcher) run() {
+	defer d.wg.Done()
+
+	for item := range d.queue {
+		_ = d.dispatcher.Dispatch(item.ctx, item.event)
+	}
+}
+
+// ScheduleEventListener dispatches the transitions of the scheduler as the published and expired events,
+// the transitions are recorded in the audit log with an auditor.
+type ScheduleEventListener struct {
+	dispatcher EventDispatcher
+	siteRepo   repository.Site
+	pageRepo   repository.Page
+	auditor    Auditor
+}
+
+func NewScheduleEventListener(dispatcher EventDispatcher, siteRepo repository.Site, pageRepo repository.Page, auditor Auditor) ScheduleEventListener {
+	if dispatcher == nil {
+		panic("event dispatcher is not specified")
+	}
+	if siteRepo == nil {
+		panic("site repository is not specified")
+	}
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	return ScheduleEventListener{
+		dispatcher: dispatcher,
+		siteRepo:   siteRepo,
+		pageRepo:   pageRepo,
+		auditor:    auditor,
+	}
+}
+
+func (l ScheduleEventListener) OnSchedule(ctx context.Context, event ScheduleEvent) error {
+	action := EventActionPublished
+	if event.Action == ScheduleActionExpired {
+		action = EventActionExpired
+	}
+
+	var e Event
+	switch event.Entity {
+	case ScheduleEntitySite:
+		m, err := l.siteRepo.FindByID(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		e = NewEntityEvent(ctx, EventEntitySite, action, m.ID, &m.ID, nil, &m)
+	case ScheduleEntityPage:
+		m, err := l.pageRepo.FindByID(WithEditor(ctx, true), event.ID)
+		if err != nil {
+			return err
+		}
+		e = NewEntityEvent(ctx, EventEntityPage, action, m.ID, &m.SiteID, nil, &m)
+	default:
+		return nil
+	}
+
+	if l.auditor != nil {
+		if err := l.auditor.Audit(ctx, e); err != nil {
+			return err
+		}
+	}
+	return l.dispatcher.Dispatch(ctx, e)
+}
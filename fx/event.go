@@ -0,0 +1,32 @@
+package fx
+
+import (
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/repository"
+)
+
+func AsEventSubscriber(f any) any {
+	return fx.Annotate(f, fx.As(new(pages.EventSubscriber)), fx.ResultTags(`group:"event-subscriber"`))
+}
+
+// AsyncEventDispatcher drains the queued events on stop.
+func AsyncEventDispatcher(subscribers []pages.EventSubscriber, logger *zap.Logger, lc fx.Lifecycle) pages.EventDispatcher {
+	dispatcher := pages.NewAsyncEventDispatcher(subscribers, logger)
+	lc.Append(fx.StopHook(dispatcher.Stop))
+	return dispatcher
+}
+
+// eventDecorator decorates the repository R with the event repository E. The decorator returns R,
+// fx ignores the decorators of the types it does not provide. The dispatcher, the auditor
+// and the logger are optional, the unit of work is required, see OptionNopUnitOfWork.
+func eventDecorator[R, E any](newRepo func(R, pages.EventDispatcher, pages.Auditor, repository.UnitOfWork, *zap.Logger) E) any {
+	return fx.Annotate(
+		func(r R, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) R {
+			return any(newRepo(r, dispatcher, auditor, uow, logger)).(R)
+		},
+		fx.ParamTags("", `optional:"true"`, `optional:"true"`, "", `optional:"true"`),
+	)
+}
@@ -48,6 +48,10 @@ func PageSkipperMiddleware(cfgRepository repository.Configuration) echox.Middlew
 	return echox.NewMiddleware("page-skipper", middleware.PageSkipper(cfgRepository))
 }
 
+func ActorMiddleware(cfg middleware.ActorConfig) echox.Middleware {
+	return echox.NewMiddleware("actor", middleware.Actor(cfg))
+}
+
 func LoggerMiddleware(cfg echox.RequestLoggerConfig, cfgRepository repository.Configuration, logger *zap.Logger) echox.Middleware {
 	skipper := cfg.Skipper
 	cfg.Skipper = func(c echo.Context) bool {
@@ -8,13 +8,16 @@ import (
 	"github.com/gowool/theme"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 
 	"github.com/gowool/pages"
 	v1 "github.com/gowool/pages/api/v1"
 	"github.com/gowool/pages/repository"
 	cacherepo "github.com/gowool/pages/repository/cache"
+	eventrepo "github.com/gowool/pages/repository/event"
 	"github.com/gowool/pages/repository/fallback"
 	fsrepo "github.com/gowool/pages/repository/fs"
+	snapshotrepo "github.com/gowool/pages/repository/snapshot"
 )
 
 var (
@@ -31,6 +34,9 @@ var (
 			fx.ParamTags("", `name:"repository-cache"`),
 		),
 	)
+	// OptionDecorateCachePageRepository has to come after OptionDecorateSnapshotPageRepository,
+	// so the snapshots served to the visitors are cached. The keys carry the editor mode and the area,
+	// the drafts do not leak to the visitors in the reverse order, but the snapshots are not cached then.
 	OptionDecorateCachePageRepository = fx.Decorate(
 		fx.Annotate(
 			cacherepo.NewPageRepository,
@@ -54,7 +60,34 @@ var (
 			fx.ParamTags("", `name:"template-fs"`),
 		),
 	)
-	OptionDecorateCacheMenuRepository = fx.Decorate(
+	// OptionDecorateSnapshotPageRepository has to come before OptionDecorateCachePageRepository.
+	OptionDecorateSnapshotPageRepository = fx.Decorate(
+		func(r repository.Page, snapshots repository.PageSnapshot) repository.Page {
+			return snapshotrepo.NewPageRepository(r, snapshots, v1.Info.Area)
+		},
+	)
+	OptionDecorateSnapshotTemplateRepository = fx.Decorate(
+		func(r repository.Template, snapshots repository.PageSnapshot) repository.Template {
+			return snapshotrepo.NewTemplateRepository(r, snapshots)
+		},
+	)
+	// OptionDecorateHookedUnitOfWork defers the events of the changes until the unit of work is committed,
+	// it is required with the event repositories.
+	OptionDecorateHookedUnitOfWork = fx.Decorate(
+		func(u repository.UnitOfWork) repository.UnitOfWork {
+			return repository.NewHookedUnitOfWork(u)
+		},
+	)
+	// The event repositories save the changes with their audit entries in one unit of work
+	// and dispatch the events once it is committed.
+	OptionDecorateEventConfigurationRepository = fx.Decorate(eventDecorator(eventrepo.NewConfigurationRepository))
+	OptionDecorateEventSiteRepository          = fx.Decorate(eventDecorator(eventrepo.NewSiteRepository))
+	OptionDecorateEventPageRepository          = fx.Decorate(eventDecorator(eventrepo.NewPageRepository))
+	OptionDecorateEventTemplateRepository      = fx.Decorate(eventDecorator(eventrepo.NewTemplateRepository))
+	OptionDecorateEventMenuRepository          = fx.Decorate(eventDecorator(eventrepo.NewMenuRepository))
+	OptionDecorateEventNodeRepository          = fx.Decorate(eventDecorator(eventrepo.NewNodeRepository))
+	OptionDecorateEventWebhookRepository       = fx.Decorate(eventDecorator(eventrepo.NewWebhookRepository))
+	OptionDecorateCacheMenuRepository          = fx.Decorate(
 		fx.Annotate(
 			cacherepo.NewMenuRepository,
 			fx.ParamTags("", `name:"repository-cache"`),
@@ -67,8 +100,139 @@ var (
 		),
 	)
 
-	OptionSeeder  = fx.Provide(fx.Annotate(pages.NewDefaultSeeder, fx.As(new(pages.Seeder))))
-	OptionMenu    = fx.Provide(fx.Annotate(pages.NewDefaultMenu, fx.As(new(pages.Menu))))
+	OptionSeeder    = fx.Provide(fx.Annotate(pages.NewDefaultSeeder, fx.As(new(pages.Seeder))))
+	OptionScheduler = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultScheduler,
+			fx.As(new(pages.Scheduler)),
+			fx.ParamTags("", "", `optional:"true"`, `name:"repository-cache"`, `group:"schedule-listener"`, `optional:"true"`),
+		),
+	)
+	// OptionScheduleRescan makes the scheduler rescan on the changes of the sites and the pages.
+	OptionScheduleRescan = fx.Provide(
+		pages.NewScheduleRescan,
+		AsEventSubscriber(func(r *pages.ScheduleRescan) *pages.ScheduleRescan { return r }),
+	)
+	OptionEventDispatcher = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultEventDispatcher,
+			fx.As(new(pages.EventDispatcher)),
+			fx.ParamTags(`group:"event-subscriber"`),
+		),
+	)
+	OptionAsyncEventDispatcher = fx.Provide(
+		fx.Annotate(
+			AsyncEventDispatcher,
+			fx.ParamTags(`group:"event-subscriber"`),
+		),
+	)
+	OptionScheduleEventListener = fx.Provide(AsScheduleListener(pages.NewScheduleEventListener, fx.ParamTags("", "", "", `optional:"true"`)))
+	OptionWebhooks              = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultWebhooks,
+			fx.As(new(pages.Webhooks)),
+			fx.ParamTags("", "", `name:"webhook-client" optional:"true"`),
+		),
+	)
+	OptionWebhookEventSubscriber = fx.Provide(AsEventSubscriber(func(w pages.Webhooks) pages.Webhooks { return w }))
+	// OptionAuditor records the changes in the audit log in their units of work,
+	// it requires the event repositories and the unit of work the audit log repository writes in.
+	OptionAuditor = fx.Provide(fx.Annotate(pages.NewDefaultAuditor, fx.As(new(pages.Auditor))))
+	// OptionNopUnitOfWork provides the pass-through unit of work to the applications without transactions,
+	// the services require a unit of work and the changes spanning several models are not atomic with this one.
+	OptionNopUnitOfWork = fx.Provide(
+		func(logger *zap.Logger) repository.UnitOfWork {
+			logger.Warn("pages: the pass-through unit of work is used, the changes are saved without transactions")
+			return repository.NopUnitOfWork{}
+		},
+	)
+	OptionPageTree = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultPageTree,
+			fx.As(new(pages.PageTree)),
+			fx.ParamTags("", "", `name:"repository-cache"`),
+		),
+	)
+	OptionPageRevisions = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultPageRevisions,
+			fx.As(new(pages.PageRevisions)),
+		),
+	)
+	OptionPagePublisher = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultPagePublisher,
+			fx.As(new(pages.PagePublisher)),
+			fx.ParamTags("", "", "", "", `name:"repository-cache"`),
+		),
+	)
+	OptionPageWorkflow = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultPageWorkflow,
+			fx.As(new(pages.PageWorkflow)),
+			fx.ParamTags("", "", "", "", "", `optional:"true"`, `name:"repository-cache"`),
+		),
+	)
+	OptionPageValidator = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultPageValidator,
+			fx.As(new(pages.PageValidator)),
+		),
+	)
+	OptionTemplateValidator = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultTemplateValidator,
+			fx.As(new(pages.TemplateValidator)),
+			fx.ParamTags("", `group:"theme-func-map"`),
+		),
+	)
+	OptionTemplateAnalyzer = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultTemplateAnalyzer,
+			fx.As(new(pages.TemplateAnalyzer)),
+		),
+	)
+	OptionTemplateRevisions = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultTemplateRevisions,
+			fx.As(new(pages.TemplateRevisions)),
+			fx.ParamTags("", "", "", "", "", `name:"repository-cache"`),
+		),
+	)
+	OptionNodeTree = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultNodeTree,
+			fx.As(new(pages.NodeTree)),
+			fx.ParamTags("", "", "", "", `name:"repository-cache"`),
+		),
+	)
+	OptionMenu = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultMenu,
+			fx.As(new(pages.Menu)),
+			fx.ParamTags("", "", "", "", `group:"menu-source"`),
+		),
+	)
+	OptionMenuCache = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultMenuCache,
+			fx.As(new(pages.MenuCache)),
+			fx.ParamTags(`name:"repository-cache"`),
+		),
+	)
+	OptionPageMenuSource = fx.Provide(
+		fx.Annotate(
+			pages.NewPageMenuSource,
+			fx.As(new(pages.MenuSource)),
+			fx.ResultTags(`group:"menu-source"`),
+		),
+	)
+	OptionBreadcrumbBuilder = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultBreadcrumbBuilder,
+			fx.As(new(pages.BreadcrumbBuilder)),
+		),
+	)
 	OptionMatcher = fx.Provide(
 		fx.Annotate(
 			pages.NewDefaultMatcher,
@@ -83,6 +247,34 @@ var (
 			fx.ResultTags(`group:"menu-voter"`),
 		),
 	)
+	OptionPageVoter = fx.Provide(
+		fx.Annotate(
+			pages.NewPageVoter,
+			fx.As(new(pages.Voter)),
+			fx.ResultTags(`group:"menu-voter"`),
+		),
+	)
+	OptionPrefixVoter = fx.Provide(
+		fx.Annotate(
+			pages.NewPrefixVoter,
+			fx.As(new(pages.Voter)),
+			fx.ResultTags(`group:"menu-voter"`),
+		),
+	)
+	OptionRegexVoter = fx.Provide(
+		fx.Annotate(
+			pages.NewRegexVoter,
+			fx.As(new(pages.Voter)),
+			fx.ResultTags(`group:"menu-voter"`),
+		),
+	)
+	OptionRoutePatternVoter = fx.Provide(
+		fx.Annotate(
+			pages.NewRoutePatternVoter,
+			fx.As(new(pages.Voter)),
+			fx.ResultTags(`group:"menu-voter"`),
+		),
+	)
 
 	OptionSiteSelector = fx.Provide(
 		fx.Annotate(
@@ -90,6 +282,19 @@ var (
 			fx.As(new(pages.SiteSelector)),
 		),
 	)
+	OptionPageDelivery = fx.Provide(
+		fx.Annotate(
+			pages.NewDefaultPageDelivery,
+			fx.As(new(pages.PageDelivery)),
+		),
+	)
+	OptionHMACPreviewVerifier = fx.Provide(
+		fx.Annotate(
+			pages.NewHMACPreviewVerifier,
+			fx.As(new(pages.PreviewVerifier)),
+			fx.ParamTags(`name:"preview-secret"`),
+		),
+	)
 	OptionPageHandler = fx.Provide(
 		fx.Annotate(
 			pages.NewDefaultPageHandler,
@@ -102,10 +307,11 @@ var (
 	OptionErrorResolver     = fx.Provide(pages.ErrorResolver)
 	OptionRenderer          = fx.Provide(fx.Annotate(pages.NewRenderer, fx.As(new(echo.Renderer))))
 
-	OptionThemeFuncMap     = fx.Provide(AsFuncMap(FuncMap))
-	OptionThemeFuncMapMenu = fx.Provide(AsFuncMap(FuncMapMenu))
-	OptionThemeFuncMapPage = fx.Provide(AsFuncMap(FuncMapPage))
-	OptionThemeLoader      = fx.Provide(fx.Annotate(theme.NewRepositoryLoader, fx.As(new(theme.Loader))))
+	OptionThemeFuncMap           = fx.Provide(AsFuncMap(FuncMap))
+	OptionThemeFuncMapMenu       = fx.Provide(AsFuncMap(FuncMapMenu))
+	OptionThemeFuncMapPage       = fx.Provide(AsFuncMap(FuncMapPage))
+	OptionThemeFuncMapBreadcrumb = fx.Provide(AsFuncMap(FuncMapBreadcrumb))
+	OptionThemeLoader            = fx.Provide(fx.Annotate(theme.NewRepositoryLoader, fx.As(new(theme.Loader))))
 
 	OptionSiteSelectorMiddleware = fx.Provide(echox.AsMiddleware(SiteSelectorMiddleware))
 	OptionPageSelectorMiddleware = fx.Provide(echox.AsMiddleware(PageSelectorMiddleware))
@@ -113,11 +319,18 @@ var (
 	OptionSiteSkipperMiddleware  = fx.Provide(echox.AsMiddleware(SiteSkipperMiddleware))
 	OptionPageSkipperMiddleware  = fx.Provide(echox.AsMiddleware(PageSkipperMiddleware))
 	OptionLoggerMiddleware       = fx.Provide(echox.AsMiddleware(LoggerMiddleware))
+	OptionActorMiddleware        = fx.Provide(echox.AsMiddleware(ActorMiddleware))
 
 	OptionConfigurationAPI = fx.Provide(api.AsHandler(v1.NewConfiguration, fx.ParamTags("", "", `group:"api-option"`)))
-	OptionMenuAPI          = fx.Provide(api.AsHandler(v1.NewMenu, fx.ParamTags("", "", `group:"api-option"`)))
-	OptionNodeAPI          = fx.Provide(api.AsHandler(v1.NewNode, fx.ParamTags("", "", `group:"api-option"`)))
-	OptionPageAPI          = fx.Provide(api.AsHandler(v1.NewPage, fx.ParamTags("", "", `group:"api-option"`)))
+	OptionMenuAPI          = fx.Provide(api.AsHandler(v1.NewMenu, fx.ParamTags("", "", "", `group:"api-option"`)))
+	OptionPublicMenuAPI    = fx.Provide(api.AsHandler(v1.NewPublicMenu, fx.ParamTags("", "", "", "", "", `group:"api-option"`)))
+	OptionNodeAPI          = fx.Provide(api.AsHandler(v1.NewNode, fx.ParamTags("", "", "", `group:"api-option"`)))
+	OptionPageAPI          = fx.Provide(api.AsHandler(v1.NewPage, fx.ParamTags("", "", "", "", "", "", "", "", `group:"api-option"`)))
+	OptionPageWorkflowAPI  = fx.Provide(api.AsHandler(v1.NewPageWorkflow, fx.ParamTags("", "", "", "", `optional:"true"`, `optional:"true"`, "", `group:"api-option"`)))
+	OptionPublicPageAPI    = fx.Provide(api.AsHandler(v1.NewPageDelivery, fx.ParamTags("", `optional:"true"`, "", `group:"api-option"`)))
+	OptionPublishAPI       = fx.Provide(api.AsHandler(v1.NewPublish, fx.ParamTags("", "", "", `optional:"true"`, `optional:"true"`, "", `group:"api-option"`)))
 	OptionSiteAPI          = fx.Provide(api.AsHandler(v1.NewSite, fx.ParamTags("", "", `group:"api-option"`)))
-	OptionTemplateAPI      = fx.Provide(api.AsHandler(v1.NewTemplate, fx.ParamTags("", "", `group:"api-option"`)))
+	OptionWebhookAPI       = fx.Provide(api.AsHandler(v1.NewWebhook, fx.ParamTags("", "", "", "", `optional:"true"`, `optional:"true"`, "", `group:"api-option"`)))
+	OptionAuditAPI         = fx.Provide(api.AsHandler(v1.NewAudit, fx.ParamTags("", "", `group:"api-option"`)))
+	OptionTemplateAPI      = fx.Provide(api.AsHandler(v1.NewTemplate, fx.ParamTags("", "", "", "", "", "", `optional:"true"`, `optional:"true"`, "", `group:"api-option"`)))
 )
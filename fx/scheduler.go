@@ -0,0 +1,16 @@
+package fx
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gowool/pages"
+)
+
+func SchedulerBoot(scheduler pages.Scheduler, lc fx.Lifecycle) {
+	lc.Append(fx.StartStopHook(scheduler.Start, scheduler.Stop))
+}
+
+func AsScheduleListener(f any, anns ...fx.Annotation) any {
+	anns = append(anns, fx.As(new(pages.ScheduleListener)), fx.ResultTags(`group:"schedule-listener"`))
+	return fx.Annotate(f, anns...)
+}
@@ -17,10 +17,14 @@ func FuncMap() theme.FuncMap {
 	return pagesheme.NewFuncMap().FuncMap
 }
 
-func FuncMapMenu(menu pages.Menu, matcher pages.Matcher) theme.FuncMap {
-	return pagesheme.NewFuncMapMenu(menu, matcher).FuncMap
+func FuncMapMenu(menu pages.Menu, matcher pages.Matcher, menuCache pages.MenuCache) theme.FuncMap {
+	return pagesheme.NewFuncMapMenu(menu, matcher, menuCache).FuncMap
 }
 
 func FuncMapPage(pageRepo repository.Page) theme.FuncMap {
 	return pagesheme.NewFuncMapPage(pageRepo).FuncMap
 }
+
+func FuncMapBreadcrumb(builder pages.BreadcrumbBuilder) theme.FuncMap {
+	return pagesheme.NewFuncMapBreadcrumb(builder).FuncMap
+}
@@ -0,0 +1,20 @@
+package fx
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gowool/pages"
+)
+
+func AsVoter(f any) any {
+	return fx.Annotate(f, fx.As(new(pages.Voter)), fx.ResultTags(`group:"menu-voter"`))
+}
+
+// OptionVoterWithPriority provides the voter to the matcher with the overridden priority.
+// It replaces the option of the voter, e.g. OptionVoterWithPriority(pages.NewPrefixVoter(), 500)
+// is used instead of OptionPrefixVoter to ask the prefix voter first.
+func OptionVoterWithPriority(voter pages.Voter, priority int) fx.Option {
+	return fx.Provide(AsVoter(func() pages.PrioritizedVoter {
+		return pages.WithVoterPriority(voter, priority)
+	}))
+}
@@ -0,0 +1,11 @@
+package fx
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/gowool/pages"
+)
+
+func WebhooksBoot(webhooks pages.Webhooks, lc fx.Lifecycle) {
+	lc.Append(fx.StartStopHook(webhooks.Start, webhooks.Stop))
+}
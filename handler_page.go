@@ -2,12 +2,20 @@ package pages
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages/model"
 )
 
+// PublicDataKey is the template data key with the extra data keys exposed in the json representation.
+const PublicDataKey = "public_data"
+
 var _ PageHandler = (*DefaultPageHandler)(nil)
 
 type PageHandler interface {
@@ -40,6 +48,16 @@ func (h *DefaultPageHandler) Handle(c echo.Context) error {
 		status = s
 	}
 
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+
+	if WantsJSON(c.Request()) {
+		for key, value := range page.Headers {
+			c.Response().Header().Set(key, value)
+		}
+		c.Response().Header().Del(echo.HeaderContentType)
+		return c.JSON(status, NewPageJSON(ctx, site, page))
+	}
+
 	if page.ContentType == "" {
 		return c.Render(status, page.Template, nil)
 	}
@@ -50,3 +68,120 @@ func (h *DefaultPageHandler) Handle(c echo.Context) error {
 	}
 	return c.Blob(status, page.ContentType, buf.Bytes())
 }
+
+// WantsJSON reports whether the request asks for the json representation of the page
+// by the Accept header or the _format=json query param.
+func WantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("_format"); format != "" {
+		return strings.EqualFold(format, "json")
+	}
+	accept := r.Header.Get(echo.HeaderAccept)
+	return strings.Contains(accept, echo.MIMEApplicationJSON) && !strings.Contains(accept, echo.MIMETextHTML)
+}
+
+type PageJSONSite struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Title        string `json:"title,omitempty"`
+	Locale       string `json:"locale,omitempty"`
+	RelativePath string `json:"relativePath,omitempty"`
+	URL          string `json:"url"`
+}
+
+type PageJSONPage struct {
+	ID       int64             `json:"id"`
+	ParentID *int64            `json:"parentID,omitempty"`
+	Name     string            `json:"name"`
+	Title    string            `json:"title,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type PageJSONSEO struct {
+	Title          string                       `json:"title,omitempty"`
+	Metas          map[string]map[string]string `json:"metas,omitempty"`
+	Canonical      string                       `json:"canonical,omitempty"`
+	LangAlternates map[string]string            `json:"langAlternates,omitempty"`
+}
+
+// PageJSON is the json representation of the page.
+// Only the data and metadata keys marked as public are exposed, see PublicDataKeys.
+type PageJSON struct {
+	Site        PageJSONSite     `json:"site"`
+	Page        PageJSONPage     `json:"page"`
+	SEO         PageJSONSEO      `json:"seo"`
+	Breadcrumbs []BreadcrumbItem `json:"breadcrumbs,omitempty"`
+	Data        map[string]any   `json:"data,omitempty"`
+}
+
+func NewPageJSON(ctx context.Context, site *model.Site, page *model.Page) PageJSON {
+	s := CtxSEO(ctx).Site(site).Page(page)
+
+	var url string
+	if page.URL != "" && !page.IsDynamic() {
+		url = PageURI(site, *page)
+	}
+
+	data := CtxData(ctx)
+	public := make(map[string]any)
+	var metadata map[string]string
+	for _, key := range PublicDataKeys(page, data) {
+		if value, ok := data[key]; ok {
+			public[key] = value
+		}
+		if value, ok := page.Metadata[key]; ok {
+			if metadata == nil {
+				metadata = make(map[string]string)
+			}
+			metadata[key] = value
+		}
+	}
+
+	return PageJSON{
+		Site: PageJSONSite{
+			ID:           site.ID,
+			Name:         site.Name,
+			Title:        site.Title,
+			Locale:       site.Locale,
+			RelativePath: site.RelativePath,
+			URL:          site.URL(),
+		},
+		Page: PageJSONPage{
+			ID:       page.ID,
+			ParentID: page.ParentID,
+			Name:     page.Name,
+			Title:    page.Title,
+			URL:      url,
+			Template: page.Template,
+			Metadata: metadata,
+		},
+		SEO: PageJSONSEO{
+			Title:          s.Title(),
+			Metas:          s.Metas(),
+			Canonical:      s.LinkCanonical(),
+			LangAlternates: s.LangAlternates(),
+		},
+		Breadcrumbs: CtxBreadcrumbs(ctx).Items(),
+		Data:        public,
+	}
+}
+
+// PublicDataKeys returns the data keys listed in the comma separated public_data page metadata
+// and the ones added by controllers to the public_data template data.
+func PublicDataKeys(page *model.Page, data map[string]any) []string {
+	var keys []string
+	if page != nil {
+		for _, key := range strings.Split(page.Metadata[PublicDataKey], ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	if extra, ok := data[PublicDataKey].([]string); ok {
+		keys = append(keys, extra...)
+	}
+
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
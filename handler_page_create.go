@@ -1,13 +1,14 @@
 package pages
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/gowool/pages/model"
-	"github.com/gowool/pages/repository"
 )
 
 type PageCreateRequest struct {
@@ -17,21 +18,44 @@ type PageCreateRequest struct {
 	Title    string `json:"title,omitempty" form:"title,omitempty" validate:"max=254"`
 }
 
+// PageCreateHandler creates the pages through the page tree, the workflow and the revisions,
+// the same way the admin api saves them.
 type PageCreateHandler struct {
-	validator Validator
-	pageRepo  repository.Page
+	validator     Validator
+	pageValidator PageValidator
+	pageTree      PageTree
+	workflow      PageWorkflow
+	revisions     PageRevisions
 }
 
-func NewPageCreateHandler(validator Validator, pageRepo repository.Page) *PageCreateHandler {
+func NewPageCreateHandler(
+	validator Validator,
+	pageValidator PageValidator,
+	pageTree PageTree,
+	workflow PageWorkflow,
+	revisions PageRevisions,
+) *PageCreateHandler {
 	if validator == nil {
 		panic("validator is not specified")
 	}
-	if pageRepo == nil {
-		panic("page repository is not specified")
+	if pageValidator == nil {
+		panic("page validator is not specified")
+	}
+	if pageTree == nil {
+		panic("page tree is not specified")
+	}
+	if workflow == nil {
+		panic("page workflow is not specified")
+	}
+	if revisions == nil {
+		panic("page revisions is not specified")
 	}
 	return &PageCreateHandler{
-		validator: validator,
-		pageRepo:  pageRepo,
+		validator:     validator,
+		pageValidator: pageValidator,
+		pageTree:      pageTree,
+		workflow:      workflow,
+		revisions:     revisions,
 	}
 }
 
@@ -64,7 +88,22 @@ func (h *PageCreateHandler) Handle(c echo.Context) error {
 		Decorate:  true,
 	}
 
-	if err := h.pageRepo.Create(c.Request().Context(), &page); err != nil {
+	if err := h.pageValidator.Validate(c.Request().Context(), page, c.Echo().Routes()); err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			for i := range validationErr.Errors {
+				if validationErr.Errors[i].Field == "customURL" {
+					validationErr.Errors[i].Field = "url"
+				}
+			}
+			return echo.NewHTTPError(http.StatusUnprocessableEntity, validationErr.Error()).WithInternal(err)
+		}
+		return err
+	}
+
+	if err := h.workflow.Save(c.Request().Context(), &page, func(ctx context.Context, m *model.Page) error {
+		return h.revisions.Save(ctx, m, "Create page", h.pageTree.Create)
+	}); err != nil {
 		return err
 	}
 
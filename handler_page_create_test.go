@@ -0,0 +1,54 @@
+package pages
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+type testValidator struct{}
+
+func (testValidator) ValidateCtx(context.Context, any) error {
+	return nil
+}
+
+func TestPageCreateHandlerSavesThroughPipeline(t *testing.T) {
+	w := newTestWorkflow(t)
+	tree := NewDefaultPageTree(w.pageRepo, &memory.UnitOfWork{}, nil)
+	validator := NewDefaultPageValidator(w.pageRepo, memory.NewConfigurationRepository(model.Configuration{}))
+	h := NewPageCreateHandler(testValidator{}, validator, tree, w, w.revisions)
+
+	body := `{"site_id":1,"url":"/docs/intro","template":"page.html"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req = req.WithContext(WithActor(WithEditor(req.Context(), true), "alice"))
+	rec := httptest.NewRecorder()
+
+	e := echo.New()
+	if err := h.Handle(e.NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusSeeOther || rec.Header().Get(echo.HeaderLocation) != "/docs/intro" {
+		t.Fatalf("response = %d %q, want the redirect to the page", rec.Code, rec.Header().Get(echo.HeaderLocation))
+	}
+
+	m, err := w.pageRepo.FindOne(func(p model.Page) bool { return p.URL == "/docs/intro" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.State != model.WorkflowDraft {
+		t.Errorf("state = %q, want %q", m.State, model.WorkflowDraft)
+	}
+
+	revisions := w.revisions.revisionRepo.(*memory.Repository[model.PageRevision]).FindAll(func(r model.PageRevision) bool { return r.PageID == m.ID })
+	if len(revisions) != 1 || revisions[0].Author != "alice" {
+		t.Errorf("revisions = %+v, want the revision of the created page", revisions)
+	}
+}
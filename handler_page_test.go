@@ -0,0 +1,83 @@
+package pages
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages/model"
+)
+
+func TestWantsJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		want   bool
+	}{
+		{name: "html", target: "/", accept: "text/html,application/xhtml+xml", want: false},
+		{name: "json accept", target: "/", accept: "application/json", want: true},
+		{name: "html and json accept", target: "/", accept: "text/html,application/json", want: false},
+		{name: "json format", target: "/?_format=json", accept: "text/html", want: true},
+		{name: "json format case", target: "/?_format=JSON", want: true},
+		{name: "html format over json accept", target: "/?_format=html", accept: "application/json", want: false},
+		{name: "no accept", target: "/", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.target, nil)
+			if tt.accept != "" {
+				r.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			if got := WantsJSON(r); got != tt.want {
+				t.Errorf("WantsJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPageJSONExposesPublicKeysOnly(t *testing.T) {
+	site := &model.Site{ID: 1, Name: "Main", Host: "example.com"}
+	page := &model.Page{
+		ID:   2,
+		Name: "Blog",
+		URL:  "/blog",
+		Metadata: map[string]string{
+			PublicDataKey:                 "description, rating",
+			"description":                 "The blog",
+			"author":                      "alice",
+			model.PageMetadataMenuExclude: "true",
+		},
+	}
+	ctx := WithData(context.Background(), map[string]any{
+		PublicDataKey: []string{"posts"},
+		"posts":       []string{"first"},
+		"rating":      5,
+		"secret":      "token",
+	})
+
+	got := NewPageJSON(ctx, site, page)
+
+	if want := map[string]string{"description": "The blog"}; !reflect.DeepEqual(got.Page.Metadata, want) {
+		t.Errorf("metadata = %v, want %v", got.Page.Metadata, want)
+	}
+	if want := map[string]any{"posts": []string{"first"}, "rating": 5}; !reflect.DeepEqual(got.Data, want) {
+		t.Errorf("data = %v, want %v", got.Data, want)
+	}
+}
+
+func TestPublicDataKeys(t *testing.T) {
+	page := &model.Page{Metadata: map[string]string{PublicDataKey: "b, a,,b"}}
+	data := map[string]any{PublicDataKey: []string{"c", "a"}}
+
+	if got, want := PublicDataKeys(page, data), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PublicDataKeys() = %v, want %v", got, want)
+	}
+	if got := PublicDataKeys(nil, nil); got != nil {
+		t.Errorf("PublicDataKeys(nil, nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+	a, b int // line numbers (0-based) in the old and new texts
+}
+
+// UnifiedDiff returns the unified diff of the texts with n lines of context.
+// It returns an empty string when the texts are equal.
+func UnifiedDiff(fromName, toName, a, b string, n int) string {
+	if a == b {
+		return ""
+	}
+
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	_, _ = fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	var changes []int
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	for k := 0; k < len(changes); k++ {
+		// merge the changes separated by at most 2*n lines of context into one hunk
+		first, last := changes[k], changes[k]
+		for k+1 < len(changes) && changes[k+1]-last-1 <= 2*n {
+			k++
+			last = changes[k]
+		}
+		start, end := max(first-n, 0), min(last+n+1, len(ops))
+
+		aStart, bStart := ops[start].a+1, ops[start].b+1
+		var aLen, bLen int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aLen++
+			}
+			if op.kind != '-' {
+				bLen++
+			}
+		}
+		if aLen == 0 {
+			aStart--
+		}
+		if bLen == 0 {
+			bStart--
+		}
+
+		_, _ = fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
+
+func hunkRange(start, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// maxDiffCost bounds the number of edits searched by diffLines,
+// the memory of the search grows with the square of the edits.
+const maxDiffCost = 1024
+
+// diffLines computes the edit script of the lines with the Myers algorithm.
+// The texts differing by more than maxDiffCost edits fall back to deleting and adding
+// all the lines between their common prefix and suffix.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{kind: ' ', line: a[i], a: i, b: i})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+	for k := suffix; k > 0; k-- {
+		i, j := len(a)-k, len(b)-k
+		ops = append(ops, diffOp{kind: ' ', line: a[i], a: i, b: j})
+	}
+	return ops
+}
+
+// myers returns the shortest edit script of the lines, offset is the line number of their first lines.
+func myers(a, b []string, offset int) []diffOp {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil
+	}
+
+	// v holds the furthest x reached on each diagonal k = x - y,
+	// trace holds the diagonals -d..d of v before each step d
+	v := make([]int, 2*(n+m)+1)
+	mid := n + m
+	var trace [][]int
+
+	for d := 0; d <= min(n+m, maxDiffCost); d++ {
+		trace = append(trace, append([]int(nil), v[mid-d:mid+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[mid+k-1] < v[mid+k+1]) {
+				x = v[mid+k+1]
+			} else {
+				x = v[mid+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[mid+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset)
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	for i := range a {
+		ops = append(ops, diffOp{kind: '-', line: a[i], a: offset + i, b: offset})
+	}
+	for j := range b {
+		ops = append(ops, diffOp{kind: '+', line: b[j], a: offset + n, b: offset + j})
+	}
+	return ops
+}
+
+func backtrack(trace [][]int, a, b []string, offset int) []diffOp {
+	x, y := len(a), len(b)
+	ops := make([]diffOp, 0, len(a)+len(b))
+
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: ' ', line: a[x], a: offset + x, b: offset + y})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{kind: '+', line: b[y], a: offset + x, b: offset + y})
+		} else {
+			x--
+			ops = append(ops, diffOp{kind: '-', line: a[x], a: offset + x, b: offset + y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{kind: ' ', line: a[x], a: offset + x, b: offset + y})
+	}
+
+	slices.Reverse(ops)
+	return ops
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n"},
+		{
+			name: "changed line",
+			a:    "a\nb\nc\n",
+			b:    "a\nx\nc\n",
+			want: "--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "added to empty",
+			a:    "",
+			b:    "a\n",
+			want: "--- from\n+++ to\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "0\n2\n3\n4\n5\n6\n7\n8\n10\n",
+			want: "--- from\n+++ to\n@@ -1,2 +1,2 @@\n-1\n+0\n 2\n@@ -8,2 +8,2 @@\n 8\n-9\n+10\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("from", "to", tt.a, tt.b, 1); got != tt.want {
+				t.Errorf("diff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesShortestScript(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+
+	ops := diffLines(a, b)
+
+	var edits int
+	var gotA, gotB []string
+	for _, op := range ops {
+		if op.kind != ' ' {
+			edits++
+		}
+		if op.kind != '+' {
+			gotA = append(gotA, op.line)
+		}
+		if op.kind != '-' {
+			gotB = append(gotB, op.line)
+		}
+	}
+	if edits != 5 {
+		t.Errorf("edits = %d, want 5", edits)
+	}
+	if strings.Join(gotA, " ") != strings.Join(a, " ") || strings.Join(gotB, " ") != strings.Join(b, " ") {
+		t.Errorf("script does not rebuild the texts: %v %v", gotA, gotB)
+	}
+}
+
+func TestDiffLinesBoundedCost(t *testing.T) {
+	a := make([]string, maxDiffCost)
+	b := make([]string, maxDiffCost)
+	for i := range a {
+		a[i] = "a" + strings.Repeat("x", i)
+		b[i] = "b" + strings.Repeat("x", i)
+	}
+	a = append([]string{"head"}, append(a, "tail")...)
+	b = append([]string{"head"}, append(b, "tail")...)
+
+	ops := diffLines(a, b)
+	if len(ops) != 2+2*maxDiffCost {
+		t.Fatalf("ops = %d, want %d", len(ops), 2+2*maxDiffCost)
+	}
+	if ops[0].kind != ' ' || ops[1].kind != '-' || ops[maxDiffCost+1].kind != '+' || ops[len(ops)-1].kind != ' ' {
+		t.Errorf("unexpected fallback script")
+	}
+}
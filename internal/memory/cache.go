@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+var ErrCacheMiss = errors.New("memory: cache miss")
+
+// Cache stores the JSON of the values with their tags.
+type Cache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	tags   map[string]map[string]struct{}
+	purged []string
+}
+
+func NewCache() *Cache {
+	return &Cache{values: map[string][]byte{}, tags: map[string]map[string]struct{}{}}
+}
+
+func (c *Cache) Set(_ context.Context, key string, value any, tags ...string) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key] = raw
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]struct{}{}
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+func (c *Cache) Get(_ context.Context, key string, value any) error {
+	c.mu.Lock()
+	raw, ok := c.values[key]
+	c.mu.Unlock()
+
+	if !ok {
+		return ErrCacheMiss
+	}
+	return json.Unmarshal(raw, value)
+}
+
+func (c *Cache) DelByKey(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.values, key)
+	return nil
+}
+
+func (c *Cache) DelByTag(_ context.Context, tag string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.purged = append(c.purged, tag)
+	for key := range c.tags[tag] {
+		delete(c.values, key)
+	}
+	delete(c.tags, tag)
+	return nil
+}
+
+// Len returns the number of the cached values.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.values)
+}
+
+// Purged returns the purged tags in order.
+func (c *Cache) Purged() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.purged...)
+}
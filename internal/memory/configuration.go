@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gowool/pages/model"
+)
+
+type ConfigurationRepository struct {
+	mu  sync.Mutex
+	cfg model.Configuration
+}
+
+func NewConfigurationRepository(cfg model.Configuration) *ConfigurationRepository {
+	return &ConfigurationRepository{cfg: cfg}
+}
+
+func (r *ConfigurationRepository) Load(context.Context) (model.Configuration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.cfg, nil
+}
+
+func (r *ConfigurationRepository) Save(_ context.Context, m *model.Configuration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cfg = *m
+	return nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gowool/pages/model"
+)
+
+type NodeRepository struct {
+	*Repository[model.Node]
+}
+
+func NewNodeRepository(notFound error) NodeRepository {
+	return NodeRepository{Repository: NewRepository[model.Node](notFound)}
+}
+
+func (r NodeRepository) FindWithChildren(ctx context.Context, id int64) ([]model.Node, error) {
+	node, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindAll(func(m model.Node) bool {
+		return m.ID == node.ID || strings.HasPrefix(m.Path, node.Path+"/")
+	}), nil
+}
+
+type MenuRepository struct {
+	*Repository[model.Menu]
+}
+
+func NewMenuRepository(notFound error) MenuRepository {
+	return MenuRepository{Repository: NewRepository[model.Menu](notFound)}
+}
+
+func (r MenuRepository) FindByHandle(_ context.Context, siteID *int64, handle string) (model.Menu, error) {
+	return r.FindOne(func(m model.Menu) bool {
+		if m.Handle != handle {
+			return false
+		}
+		if siteID == nil || m.SiteID == nil {
+			return siteID == m.SiteID
+		}
+		return *siteID == *m.SiteID
+	})
+}
+
+// Sequence allocates the node IDs.
+type Sequence struct {
+	mu      sync.Mutex
+	last    int64
+	Deleted []int64
+}
+
+func (s *Sequence) Create(context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.last++
+	return s.last, nil
+}
+
+func (s *Sequence) Delete(_ context.Context, ids ...int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Deleted = append(s.Deleted, ids...)
+	return nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/gowool/pages/model"
+)
+
+type PageRepository struct {
+	*Repository[model.Page]
+}
+
+func NewPageRepository(notFound error) PageRepository {
+	return PageRepository{Repository: NewRepository[model.Page](notFound)}
+}
+
+func (r PageRepository) FindByParentID(_ context.Context, parentID int64, now time.Time) ([]model.Page, error) {
+	data := r.FindAll(func(m model.Page) bool {
+		return m.ParentID != nil && *m.ParentID == parentID && enabled(m, now)
+	})
+	if len(data) == 0 {
+		return nil, r.NotFound
+	}
+	return data, nil
+}
+
+func (r PageRepository) FindByPattern(_ context.Context, siteID int64, pattern string, now time.Time) (model.Page, error) {
+	return r.FindOne(func(m model.Page) bool {
+		return m.SiteID == siteID && m.Pattern == pattern && enabled(m, now)
+	})
+}
+
+func (r PageRepository) FindByAlias(_ context.Context, siteID int64, alias string, now time.Time) (model.Page, error) {
+	return r.FindOne(func(m model.Page) bool {
+		return m.SiteID == siteID && m.Alias == alias && enabled(m, now)
+	})
+}
+
+func (r PageRepository) FindByURL(_ context.Context, siteID int64, url string, now time.Time) (model.Page, error) {
+	return r.FindOne(func(m model.Page) bool {
+		return m.SiteID == siteID && m.URL == url && enabled(m, now)
+	})
+}
+
+func enabled(m model.Page, now time.Time) bool {
+	return now.IsZero() || m.IsEnabled(now)
+}
+
+type PageSnapshotRepository struct {
+	*Repository[model.PageSnapshot]
+}
+
+func NewPageSnapshotRepository(notFound error) PageSnapshotRepository {
+	return PageSnapshotRepository{Repository: NewRepository[model.PageSnapshot](notFound)}
+}
+
+func (r PageSnapshotRepository) FindByParentID(_ context.Context, parentID int64, now time.Time) ([]model.PageSnapshot, error) {
+	data := r.FindAll(func(s model.PageSnapshot) bool {
+		return s.ParentID != nil && *s.ParentID == parentID && enabled(s.Page, now)
+	})
+	if len(data) == 0 {
+		return nil, r.NotFound
+	}
+	return data, nil
+}
+
+func (r PageSnapshotRepository) FindByPattern(_ context.Context, siteID int64, pattern string, now time.Time) (model.PageSnapshot, error) {
+	return r.FindOne(func(s model.PageSnapshot) bool {
+		return s.SiteID == siteID && s.Pattern == pattern && enabled(s.Page, now)
+	})
+}
+
+func (r PageSnapshotRepository) FindByAlias(_ context.Context, siteID int64, alias string, now time.Time) (model.PageSnapshot, error) {
+	return r.FindOne(func(s model.PageSnapshot) bool {
+		return s.SiteID == siteID && s.Alias == alias && enabled(s.Page, now)
+	})
+}
+
+func (r PageSnapshotRepository) FindByURL(_ context.Context, siteID int64, url string, now time.Time) (model.PageSnapshot, error) {
+	return r.FindOne(func(s model.PageSnapshot) bool {
+		return s.SiteID == siteID && s.URL == url && enabled(s.Page, now)
+	})
+}
@@ -0,0 +1,313 @@
+// Package memory implements the repositories in memory for the tests of the services.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gowool/cr"
+)
+
+// Repository stores the models in memory and applies the filters and the sorting
+// of the criteria to their JSON fields, the column site_id matches the field siteID.
+type Repository[M any] struct {
+	NotFound error
+
+	mu    sync.Mutex
+	seq   int64
+	items map[int64]M
+}
+
+func NewRepository[M any](notFound error) *Repository[M] {
+	return &Repository[M]{NotFound: notFound, items: map[int64]M{}}
+}
+
+func (r *Repository[M]) Find(_ context.Context, criteria *cr.Criteria) ([]M, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := make([]M, 0, len(r.items))
+	for _, m := range r.sorted() {
+		if criteria == nil || match(fields(m), criteria.Filter) {
+			data = append(data, m)
+		}
+	}
+
+	if criteria == nil {
+		return data, nil
+	}
+
+	for i := len(criteria.SortBy) - 1; i >= 0; i-- {
+		s := criteria.SortBy[i]
+		slices.SortStableFunc(data, func(a, b M) int {
+			c := compare(fields(a)[column(s.Column)], fields(b)[column(s.Column)])
+			if strings.EqualFold(s.Order, "DESC") {
+				return -c
+			}
+			return c
+		})
+	}
+
+	offset := min(criteria.GetOffset(), len(data))
+	data = data[offset:]
+	if size := criteria.GetSize(0); size > 0 && size < len(data) {
+		data = data[:size]
+	}
+	return data, nil
+}
+
+func (r *Repository[M]) FindAndCount(ctx context.Context, criteria *cr.Criteria) ([]M, int, error) {
+	total := 0
+	if criteria != nil {
+		all, err := r.Find(ctx, &cr.Criteria{Filter: criteria.Filter})
+		if err != nil {
+			return nil, 0, err
+		}
+		total = len(all)
+	}
+
+	data, err := r.Find(ctx, criteria)
+	if criteria == nil {
+		total = len(data)
+	}
+	return data, total, err
+}
+
+func (r *Repository[M]) FindByID(_ context.Context, id int64) (m M, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.items[id]
+	if !ok {
+		return m, r.NotFound
+	}
+	return m, nil
+}
+
+// FindOne returns the first model accepted by fn in the order of ids.
+func (r *Repository[M]) FindOne(fn func(M) bool) (m M, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range r.sorted() {
+		if fn(item) {
+			return item, nil
+		}
+	}
+	return m, r.NotFound
+}
+
+// FindAll returns the models accepted by fn in the order of ids.
+func (r *Repository[M]) FindAll(fn func(M) bool) []M {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var data []M
+	for _, item := range r.sorted() {
+		if fn(item) {
+			data = append(data, item)
+		}
+	}
+	return data
+}
+
+func (r *Repository[M]) Delete(_ context.Context, ids ...int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.items, id)
+	}
+	return nil
+}
+
+func (r *Repository[M]) Create(_ context.Context, m *M) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v := reflect.ValueOf(m).Elem()
+	id := v.FieldByName("ID")
+	if id.Int() == 0 {
+		r.seq++
+		id.SetInt(r.seq)
+	} else {
+		r.seq = max(r.seq, id.Int())
+	}
+	if f := v.FieldByName("Created"); f.IsValid() && f.Interface().(time.Time).IsZero() {
+		f.Set(reflect.ValueOf(time.Now().UTC()))
+	}
+
+	r.items[id.Int()] = clone(*m)
+	return nil
+}
+
+func (r *Repository[M]) Update(_ context.Context, m *M) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := reflect.ValueOf(m).Elem().FieldByName("ID").Int()
+	if _, ok := r.items[id]; !ok {
+		return r.NotFound
+	}
+	r.items[id] = clone(*m)
+	return nil
+}
+
+// Len returns the number of the stored models.
+func (r *Repository[M]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.items)
+}
+
+func (r *Repository[M]) sorted() []M {
+	ids := make([]int64, 0, len(r.items))
+	for id := range r.items {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	data := make([]M, 0, len(ids))
+	for _, id := range ids {
+		data = append(data, clone(r.items[id]))
+	}
+	return data
+}
+
+// clone drops the references to the nested models the same way a database does.
+func clone[M any](m M) M {
+	v := reflect.ValueOf(&m).Elem()
+	for _, name := range []string{"Parent", "Children", "Site", "Menu", "Node", "Page"} {
+		f := v.FieldByName(name)
+		if f.IsValid() && (f.Kind() == reflect.Pointer || f.Kind() == reflect.Slice) {
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+	return m
+}
+
+func fields(m any) map[string]any {
+	raw, _ := json.Marshal(m)
+	data := map[string]any{}
+	_ = json.Unmarshal(raw, &data)
+	return data
+}
+
+// column converts the column name to the JSON field name, e.g. site_id to siteID.
+func column(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] == "id" {
+			parts[i] = "ID"
+		} else if parts[i] != "" {
+			r := []rune(parts[i])
+			r[0] = unicode.ToUpper(r[0])
+			parts[i] = string(r)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func match(data map[string]any, filter cr.Filter) bool {
+	if filter.IsEmpty() {
+		return true
+	}
+
+	or := filter.Operator == cr.OpOR
+	for _, item := range filter.Conditions {
+		var ok bool
+		switch c := item.(type) {
+		case cr.Condition:
+			ok = matchCondition(data, c)
+		case cr.Filter:
+			ok = match(data, c)
+		default:
+			panic(fmt.Sprintf("memory: unsupported condition %T", item))
+		}
+
+		if or && ok {
+			return true
+		}
+		if !or && !ok {
+			return false
+		}
+	}
+	return !or
+}
+
+func matchCondition(data map[string]any, c cr.Condition) bool {
+	value, ok := data[column(c.Column)]
+
+	switch c.Operator {
+	case cr.OpIS:
+		return !ok || value == nil
+	case cr.OpEmpty, cr.OpEqual:
+		return ok && compare(value, normalize(c.Value)) == 0
+	case cr.OpNotEqual:
+		return !ok || compare(value, normalize(c.Value)) != 0
+	case cr.OpGt:
+		return ok && compare(value, normalize(c.Value)) > 0
+	case cr.OpGte:
+		return ok && compare(value, normalize(c.Value)) >= 0
+	case cr.OpLt:
+		return ok && compare(value, normalize(c.Value)) < 0
+	case cr.OpLte:
+		return ok && compare(value, normalize(c.Value)) <= 0
+	case cr.OpIN:
+		values, _ := normalize(c.Value).([]any)
+		return ok && slices.ContainsFunc(values, func(v any) bool { return compare(value, v) == 0 })
+	}
+	panic(fmt.Sprintf("memory: unsupported operator %s", c.Operator))
+}
+
+func normalize(value any) any {
+	raw, _ := json.Marshal(value)
+	var v any
+	_ = json.Unmarshal(raw, &v)
+	return v
+}
+
+func compare(a, b any) int {
+	switch x := a.(type) {
+	case float64:
+		y, _ := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case string:
+		y, _ := b.(string)
+		tx, errX := time.Parse(time.RFC3339Nano, x)
+		ty, errY := time.Parse(time.RFC3339Nano, y)
+		if errX == nil && errY == nil {
+			return tx.Compare(ty)
+		}
+		return strings.Compare(x, y)
+	case bool:
+		y, _ := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case nil:
+		if b == nil {
+			return 0
+		}
+		return -1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
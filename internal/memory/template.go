@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/gowool/pages/model"
+)
+
+type TemplateRepository struct {
+	*Repository[model.Template]
+}
+
+func NewTemplateRepository(notFound error) TemplateRepository {
+	return TemplateRepository{Repository: NewRepository[model.Template](notFound)}
+}
+
+func (r TemplateRepository) FindByName(_ context.Context, name string) (model.Template, error) {
+	return r.FindOne(func(m model.Template) bool {
+		return m.Name == name
+	})
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type txKey struct{}
+
+// UnitOfWork marks the context of fn as transactional and counts the committed
+// and the rolled back units, the nested ones join the outer unit.
+type UnitOfWork struct {
+	mu         sync.Mutex
+	committed  int
+	rolledBack int
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+
+	err := fn(context.WithValue(ctx, txKey{}, true))
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err != nil {
+		u.rolledBack++
+	} else {
+		u.committed++
+	}
+	return err
+}
+
+func (u *UnitOfWork) Committed() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.committed
+}
+
+func (u *UnitOfWork) RolledBack() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.rolledBack
+}
+
+// InTx reports whether ctx is the context of a unit of work.
+func InTx(ctx context.Context) bool {
+	v, _ := ctx.Value(txKey{}).(bool)
+	return v
+}
@@ -4,40 +4,120 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gowool/pages/model"
 	"github.com/gowool/pages/repository"
 )
 
 type Menu interface {
-	Get(ctx context.Context, handle string) (model.Menu, error)
+	Get(ctx context.Context, handle string, options ...MenuOption) (model.Menu, error)
+}
+
+type MenuOptions struct {
+	// MaxDepth limits the depth of the tree below the root node, 0 means unlimited.
+	MaxDepth int
+
+	// StartLevel makes the node of the current branch at this level the root node,
+	// e.g. 1 renders the sub-navigation of the current top-level item.
+	StartLevel int
+
+	// OnlyCurrentBranch renders the children of the current node and its ancestors only.
+	OnlyCurrentBranch bool
+
+	// Display hides the nodes without Display and the children of the nodes without DisplayChildren.
+	Display bool
+}
+
+type MenuOption func(*MenuOptions)
+
+func WithMenuMaxDepth(depth int) MenuOption {
+	return func(o *MenuOptions) {
+		o.MaxDepth = depth
+	}
+}
+
+func WithMenuStartLevel(level int) MenuOption {
+	return func(o *MenuOptions) {
+		o.StartLevel = level
+	}
+}
+
+func WithMenuOnlyCurrentBranch() MenuOption {
+	return func(o *MenuOptions) {
+		o.OnlyCurrentBranch = true
+	}
+}
+
+func WithMenuDisplay() MenuOption {
+	return func(o *MenuOptions) {
+		o.Display = true
+	}
 }
 
 type DefaultMenu struct {
 	menuRepo repository.Menu
 	nodeRepo repository.Node
+	pageRepo repository.Page
+	matcher  Matcher
+	sources  map[string]MenuSource
 }
 
-func NewDefaultMenu(menuRepo repository.Menu, nodeRepo repository.Node) *DefaultMenu {
-	return &DefaultMenu{
+func NewDefaultMenu(menuRepo repository.Menu, nodeRepo repository.Node, pageRepo repository.Page, matcher Matcher, sources ...MenuSource) *DefaultMenu {
+	m := &DefaultMenu{
 		menuRepo: menuRepo,
 		nodeRepo: nodeRepo,
+		pageRepo: pageRepo,
+		matcher:  matcher,
+		sources:  make(map[string]MenuSource, len(sources)),
 	}
+	for _, source := range sources {
+		m.sources[source.Name()] = source
+	}
+	return m
 }
 
-func (m *DefaultMenu) Get(ctx context.Context, handle string) (model.Menu, error) {
-	menu, err := m.menuRepo.FindByHandle(ctx, handle)
+func (m *DefaultMenu) Get(ctx context.Context, handle string, options ...MenuOption) (model.Menu, error) {
+	menu, err := m.find(ctx, handle)
 	if err != nil {
 		return model.Menu{}, err
 	}
 
+	var opts MenuOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if menu.IsGenerated() {
+		source, ok := m.sources[menu.Source]
+		if !ok {
+			return model.Menu{}, fmt.Errorf("menu: source %q of menu %s is not registered: %w", menu.Source, menu.Handle, ErrMenuNotFound)
+		}
+		if menu.Node, err = source.Build(ctx, menu); err != nil {
+			return model.Menu{}, err
+		}
+		menu.Node = m.apply(ctx, menu.Node, opts)
+		return menu, nil
+	}
+
 	if menu.NodeID != nil {
 		data, err := m.nodeRepo.FindWithChildren(ctx, *menu.NodeID)
 		if err != nil {
 			return model.Menu{}, err
 		}
 		menu.Node = BuildTree(data, *menu.NodeID)
+
+		if menu.Node != nil {
+			if err = m.resolve(ctx, menu.Node, map[string]*model.Page{}); err != nil {
+				return model.Menu{}, err
+			}
+			menu.Node = m.apply(ctx, menu.Node, opts)
+		}
 	}
 
 	if menu.NodeID == nil {
@@ -47,6 +127,187 @@ func (m *DefaultMenu) Get(ctx context.Context, handle string) (model.Menu, error
 	return menu, nil
 }
 
+// find returns the menu of the current site and falls back to the global one.
+func (m *DefaultMenu) find(ctx context.Context, handle string) (model.Menu, error) {
+	if site := CtxSite(ctx); site != nil && site.ID > 0 {
+		menu, err := m.menuRepo.FindByHandle(ctx, &site.ID, handle)
+		if err == nil || !IsOneOfNotFound(err) {
+			return menu, err
+		}
+	}
+	return m.menuRepo.FindByHandle(ctx, nil, handle)
+}
+
+// apply marks the current branch and cuts the tree according to the options.
+// It returns nil when the current branch does not reach the start level.
+func (m *DefaultMenu) apply(ctx context.Context, root *model.Node, opts MenuOptions) *model.Node {
+	if root == nil {
+		return nil
+	}
+
+	if opts.Display {
+		displayed(root)
+	}
+
+	var path []*model.Node
+	if m.matcher != nil {
+		path = CurrentNodePath(ctx, m.matcher, root)
+		for _, node := range path[:max(0, len(path)-1)] {
+			node.Ancestor = true
+		}
+	}
+
+	if opts.StartLevel > 0 {
+		if opts.StartLevel >= len(path) {
+			return nil
+		}
+		root = path[opts.StartLevel]
+		path = path[opts.StartLevel:]
+	}
+
+	branch := make(map[*model.Node]struct{}, len(path))
+	for _, node := range path {
+		branch[node] = struct{}{}
+	}
+
+	var cut func(node *model.Node, depth int)
+	cut = func(node *model.Node, depth int) {
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			node.Children = nil
+			return
+		}
+		if _, ok := branch[node]; opts.OnlyCurrentBranch && !ok && node != root {
+			node.Children = nil
+			return
+		}
+		for _, child := range node.Children {
+			cut(child, depth+1)
+		}
+	}
+	cut(root, 0)
+
+	return root
+}
+
+func displayed(node *model.Node) {
+	if !node.DisplayChildren {
+		node.Children = nil
+		return
+	}
+
+	node.Children = slices.DeleteFunc(node.Children, func(child *model.Node) bool {
+		return !child.Display
+	})
+	for _, child := range node.Children {
+		displayed(child)
+	}
+}
+
+// CurrentNodePath returns the nodes from the root to the deepest current node,
+// or nil when none of the nodes is current.
+func CurrentNodePath(ctx context.Context, matcher Matcher, root *model.Node) []*model.Node {
+	var walk func(node *model.Node, path []*model.Node) []*model.Node
+	walk = func(node *model.Node, path []*model.Node) []*model.Node {
+		path = append(slices.Clip(path), node)
+		for _, child := range node.Children {
+			if result := walk(child, path); result != nil {
+				return result
+			}
+		}
+		if matcher.IsCurrent(ctx, node) {
+			return path
+		}
+		return nil
+	}
+	return walk(root, nil)
+}
+
+// resolve sets the URI of the nodes linked to CMS pages and removes the nodes
+// whose page is not available for the current site.
+func (m *DefaultMenu) resolve(ctx context.Context, node *model.Node, resolved map[string]*model.Page) error {
+	if node.IsPageLink() {
+		page, err := m.page(ctx, *node, resolved)
+		if err != nil {
+			return err
+		}
+		if page != nil {
+			node.Page = page
+			node.URI = PageURI(CtxSite(ctx), *page)
+		} else {
+			node.URI = ""
+		}
+	}
+
+	node.Children = slices.DeleteFunc(node.Children, func(child *model.Node) bool {
+		if !child.IsPageLink() {
+			return false
+		}
+		page, err := m.page(ctx, *child, resolved)
+		return err == nil && page == nil
+	})
+
+	for _, child := range node.Children {
+		if err := m.resolve(ctx, child, resolved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *DefaultMenu) page(ctx context.Context, node model.Node, resolved map[string]*model.Page) (*model.Page, error) {
+	key := node.PageAlias()
+	if node.PageID != nil {
+		key = strconv.FormatInt(*node.PageID, 10)
+	}
+	if page, ok := resolved[key]; ok {
+		return page, nil
+	}
+
+	site := CtxSite(ctx)
+
+	var now time.Time
+	if !CtxEditor(ctx) {
+		now = time.Now().UTC()
+	}
+
+	var (
+		page model.Page
+		err  error
+	)
+	switch {
+	case m.pageRepo == nil:
+		err = ErrPageNotFound
+	case node.PageID != nil:
+		page, err = m.pageRepo.FindByID(ctx, *node.PageID)
+	case site != nil:
+		page, err = m.pageRepo.FindByAlias(ctx, site.ID, key, now)
+	default:
+		err = ErrPageNotFound
+	}
+	if err != nil && !IsOneOfNotFound(err) {
+		return nil, err
+	}
+
+	if err != nil ||
+		(site != nil && page.SiteID != site.ID) ||
+		(!now.IsZero() && !page.IsEnabled(now)) ||
+		page.URL == "" || page.IsDynamic() {
+		resolved[key] = nil
+		return nil, nil
+	}
+
+	resolved[key] = &page
+	return &page, nil
+}
+
+// PageURI returns the url path of the page prefixed by the relative path of the site.
+func PageURI(site *model.Site, page model.Page) string {
+	if site == nil || site.RelativePath == "" || site.RelativePath == "/" {
+		return page.URL
+	}
+	return strings.TrimSuffix(site.RelativePath, "/") + "/" + strings.TrimPrefix(page.URL, "/")
+}
+
 func BuildTree(nodes []model.Node, id int64) *model.Node {
 	nodeMap := make(map[int64]*model.Node)
 	var rootNode *model.Node
@@ -0,0 +1,123 @@
+package pages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/gowool/pages/model"
+)
+
+const CachePrefixMenuFragment = "cms::menu:fragment"
+
+var _ MenuCache = (*DefaultMenuCache)(nil)
+
+// MenuCache stores the rendered menus.
+// The entries are tagged by the menu, its nodes and linked pages, so editing any of them purges the entry.
+type MenuCache interface {
+	// Key returns the cache key of the menu rendered for the current request.
+	// It depends on the menu handle, the site, its locale, the current url path and page, and the parts.
+	Key(ctx context.Context, handle string, parts ...any) string
+
+	// Get loads the entry into the value and reports whether it has been found.
+	Get(ctx context.Context, key string, value any) bool
+
+	// Set stores the value rendered from the menu.
+	Set(ctx context.Context, key string, value any, menu model.Menu)
+}
+
+type DefaultMenuCache struct {
+	cache Cache
+}
+
+func NewDefaultMenuCache(cache Cache) *DefaultMenuCache {
+	return &DefaultMenuCache{cache: cache}
+}
+
+func (c *DefaultMenuCache) Key(ctx context.Context, handle string, parts ...any) string {
+	var b strings.Builder
+	b.WriteString(handle)
+
+	if site := CtxSite(ctx); site != nil {
+		_, _ = fmt.Fprintf(&b, "|site:%d|locale:%s", site.ID, site.Locale)
+	}
+	if page := CtxPage(ctx); page != nil {
+		_, _ = fmt.Fprintf(&b, "|page:%d", page.ID)
+	}
+	_, _ = fmt.Fprintf(&b, "|url:%s|route:%s", CtxURL(ctx).Path, CtxRoutePattern(ctx))
+
+	for _, part := range parts {
+		_, _ = fmt.Fprintf(&b, "|%v", part)
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return fmt.Sprintf("%s:%s:%s", CachePrefixMenuFragment, handle, hex.EncodeToString(sum[:]))
+}
+
+func (c *DefaultMenuCache) Get(ctx context.Context, key string, value any) bool {
+	// editors see the drafts, which must never get into the cache
+	if c.cache == nil || CtxEditor(ctx) {
+		return false
+	}
+	return c.cache.Get(ctx, key, value) == nil
+}
+
+func (c *DefaultMenuCache) Set(ctx context.Context, key string, value any, menu model.Menu) {
+	if c.cache == nil || CtxEditor(ctx) {
+		return
+	}
+	_ = c.cache.Set(ctx, key, value, MenuCacheTags(ctx, menu)...)
+}
+
+// MenuCacheTags returns the tags of the rendered menu.
+//
+// Besides the menu and its nodes, the entry depends on the nodes and pages filtered out of the tree,
+// e.g. a hidden node or an unpublished page, so it is also tagged by the fragment tags
+// purged on any node change and on any page change of the current site.
+func MenuCacheTags(ctx context.Context, menu model.Menu) []string {
+	tags := []string{
+		CacheTag(CachePrefixMenu, menu.ID),
+		MenuHandleCacheTag(menu.Handle),
+		MenuFragmentNodesCacheTag(),
+	}
+	if site := CtxSite(ctx); site != nil {
+		tags = append(tags, MenuFragmentSiteCacheTag(site.ID))
+	}
+
+	var walk func(node *model.Node)
+	walk = func(node *model.Node) {
+		if node == nil {
+			return
+		}
+		if node.ID > 0 {
+			tags = append(tags, CacheTag(CachePrefixNode, node.ID))
+		}
+		if node.Page != nil {
+			tags = append(tags, CacheTag(CachePrefixPage, node.Page.ID))
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(menu.Node)
+
+	return tags
+}
+
+// MenuHandleCacheTag is purged when a menu with the handle is created or changed,
+// since a site menu replaces the global one with the same handle.
+func MenuHandleCacheTag(handle string) string {
+	return CacheTag(CachePrefixMenu, "handle:"+handle)
+}
+
+// MenuFragmentNodesCacheTag is purged on any change of the nodes.
+func MenuFragmentNodesCacheTag() string {
+	return CacheTag(CachePrefixMenuFragment, "nodes")
+}
+
+// MenuFragmentSiteCacheTag is purged on any change of the site pages.
+func MenuFragmentSiteCacheTag(siteID int64) string {
+	return CacheTag(CachePrefixMenuFragment, fmt.Sprintf("site:%d", siteID))
+}
@@ -0,0 +1,162 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gowool/cr"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+const MenuSourcePages = "pages"
+
+// MenuSource builds the node tree of a menu on the fly.
+type MenuSource interface {
+	// Name returns the value of model.Menu.Source handled by the source.
+	Name() string
+
+	// Build returns the root node of the menu.
+	Build(ctx context.Context, menu model.Menu) (*model.Node, error)
+}
+
+var _ MenuSource = (*PageMenuSource)(nil)
+
+// PageMenuSource builds menus from the page tree of the current site.
+// Generated nodes have negative IDs, so they never clash with the stored ones.
+type PageMenuSource struct {
+	pageRepo repository.Page
+}
+
+func NewPageMenuSource(pageRepo repository.Page) *PageMenuSource {
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	return &PageMenuSource{pageRepo: pageRepo}
+}
+
+func (s *PageMenuSource) Name() string {
+	return MenuSourcePages
+}
+
+func (s *PageMenuSource) Build(ctx context.Context, menu model.Menu) (*model.Node, error) {
+	site := CtxSite(ctx)
+	if site == nil {
+		return nil, ErrSiteNotFound
+	}
+
+	var now time.Time
+	if !CtxEditor(ctx) {
+		now = time.Now().UTC()
+	}
+
+	data, err := s.pageRepo.Find(ctx, cr.New().SetFilter(cr.Filter{
+		Conditions: []any{cr.Condition{Column: "site_id", Operator: cr.OpEqual, Value: site.ID}},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	root := &model.Node{
+		Name:            menu.Handle,
+		Label:           menu.Name,
+		Path:            "0",
+		Display:         true,
+		DisplayChildren: true,
+		Menu:            &menu,
+	}
+
+	children := BuildPageTree(data)
+	if menu.RootPage != "" {
+		page, ok := findPage(children, menu.RootPage)
+		if !ok || menuExcluded(page, now) {
+			return nil, fmt.Errorf("menu source: root page %q of menu %s: %w", menu.RootPage, menu.Handle, ErrPageNotFound)
+		}
+		root = s.node(site, page, nil, &menu)
+		children = page.Children
+	}
+
+	s.nest(site, root, children, menu.Depth, now, &menu)
+	return root, nil
+}
+
+// menuExcluded reports whether the page and its descendants cannot be linked from a menu.
+func menuExcluded(page model.Page, now time.Time) bool {
+	return page.IsInternal() || page.IsError() || page.IsMenuExcluded() || (!now.IsZero() && !page.IsEnabled(now))
+}
+
+func (s *PageMenuSource) nest(site *model.Site, parent *model.Node, children []model.Page, depth int, now time.Time, menu *model.Menu) {
+	if depth > 0 && parent.Level >= depth {
+		return
+	}
+
+	for _, page := range children {
+		if menuExcluded(page, now) || page.IsDynamic() || page.URL == "" {
+			continue
+		}
+		child := s.node(site, page, parent, menu)
+		parent.Children = append(parent.Children, child)
+		s.nest(site, child, page.Children, depth, now, menu)
+	}
+}
+
+func (s *PageMenuSource) node(site *model.Site, page model.Page, parent *model.Node, menu *model.Menu) *model.Node {
+	label := page.Title
+	if label == "" {
+		label = page.Name
+	}
+
+	page.Site = site
+	page.Parent = nil
+	page.Children = nil
+
+	n := &model.Node{
+		ID:              -page.ID,
+		Name:            page.Name,
+		Label:           label,
+		URI:             PageURI(site, page),
+		PageID:          &page.ID,
+		Position:        page.Position,
+		Display:         true,
+		DisplayChildren: true,
+		Metadata:        page.Metadata,
+		Created:         page.Created,
+		Updated:         page.Updated,
+		Parent:          parent,
+		Menu:            menu,
+		Page:            &page,
+	}
+	if parent != nil {
+		n.ParentID = parent.ID
+		n.Level = parent.Level + 1
+		n.Path = fmt.Sprintf("%s/%d", parent.Path, n.ID)
+	} else {
+		n.Path = strconv.FormatInt(n.ID, 10)
+	}
+	return n
+}
+
+// findPage searches the page tree for the page with the ID or alias.
+func findPage(data []model.Page, idOrAlias string) (model.Page, bool) {
+	id, err := strconv.ParseInt(idOrAlias, 10, 64)
+	if err != nil {
+		idOrAlias = model.Page{}.WithAlias(idOrAlias).Alias
+	}
+
+	var (
+		result model.Page
+		found  bool
+	)
+	walkPages(data, func(p model.Page) {
+		if found {
+			return
+		}
+		if (err == nil && p.ID == id) || (err != nil && p.Alias == idOrAlias) {
+			result, found = p, true
+		}
+	})
+	return result, found
+}
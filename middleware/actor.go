@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/gowool/pages"
+)
+
+type ActorConfig struct {
+	Skipper middleware.Skipper
+
+	// Actor returns the identity of the authenticated user making the request.
+	Actor func(c echo.Context) string
+
+	// Roles returns the workflow roles of the user, optional.
+	Roles func(c echo.Context) []string
+}
+
+// Actor sets the actor and the roles of the request, recorded by the revisions, the workflow and the audit log.
+func Actor(cfg ActorConfig) echo.MiddlewareFunc {
+	if cfg.Actor == nil {
+		panic("actor func is not specified")
+	}
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			ctx := pages.WithActor(c.Request().Context(), cfg.Actor(c))
+			if cfg.Roles != nil {
+				ctx = pages.WithRoles(ctx, cfg.Roles(c)...)
+			}
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
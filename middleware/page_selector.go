@@ -90,6 +90,9 @@ func PageSelector(cfg PageSelectorConfig) echo.MiddlewareFunc {
 func withPage(c echo.Context, next echo.HandlerFunc, page model.Page) error {
 	r := c.Request()
 	ctx := pages.WithPage(r.Context(), &page)
+	if pages.CtxBreadcrumbs(ctx) == nil {
+		ctx = pages.WithBreadcrumbs(ctx, pages.NewBreadcrumbs())
+	}
 	c.SetRequest(r.WithContext(ctx))
 	return next(c)
 }
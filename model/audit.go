@@ -0,0 +1,32 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type FieldChange struct {
+	Field string `json:"field" yaml:"field"`
+	From  any    `json:"from,omitempty" yaml:"from,omitempty"`
+	To    any    `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+// AuditEntry records a change of an entity made by the actor.
+type AuditEntry struct {
+	ID       int64         `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	Actor    string        `json:"actor,omitempty" yaml:"actor,omitempty" required:"false"`
+	Entity   string        `json:"entity" yaml:"entity" required:"true"`
+	EntityID int64         `json:"entityID,omitempty" yaml:"entityID,omitempty" required:"false"`
+	SiteID   *int64        `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"false"`
+	Action   string        `json:"action" yaml:"action" required:"true"`
+	Changes  []FieldChange `json:"changes" yaml:"changes" required:"true"`
+	Created  time.Time     `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
+}
+
+func (e AuditEntry) GetID() int64 {
+	return e.ID
+}
+
+func (e AuditEntry) String() string {
+	return fmt.Sprintf("%s %s#%d", e.Action, e.Entity, e.EntityID)
+}
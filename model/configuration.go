@@ -55,16 +55,18 @@ func (s *Skippers) Skipper(c echo.Context) bool {
 }
 
 type Configuration struct {
-	Debug                 bool              `json:"debug,omitempty" yaml:"debug,omitempty" required:"true"`
-	Multisite             MultisiteStrategy `json:"multisite,omitempty" yaml:"multisite,omitempty" required:"false" enum:"host,host-by-locale,host-with-path,host-with-path-by-locale"`
-	FallbackLocale        string            `json:"fallbackLocale,omitempty" yaml:"fallbackLocale,omitempty" required:"false"`
-	IgnoreRequestPatterns []string          `json:"ignoreRequestPatterns,omitempty" yaml:"ignoreRequestPatterns,omitempty" required:"false"`
-	IgnoreRequestURIs     []string          `json:"ignoreRequestURIs,omitempty" yaml:"ignoreRequestURIs,omitempty" required:"false"`
-	SiteSkippers          *Skippers         `json:"siteSkippers,omitempty" yaml:"siteSkippers,omitempty" required:"false"`
-	PageSkippers          *Skippers         `json:"pageSkippers,omitempty" yaml:"pageSkippers,omitempty" required:"false"`
-	LoggerSkippers        *Skippers         `json:"loggerSkippers,omitempty" yaml:"loggerSkippers,omitempty" required:"false"`
-	CatchErrors           map[string][]int  `json:"catchErrors,omitempty" yaml:"catchErrors,omitempty" required:"false"`
-	Additional            map[string]string `json:"additional,omitempty" yaml:"additional,omitempty" required:"false"`
+	Debug                 bool                 `json:"debug,omitempty" yaml:"debug,omitempty" required:"true"`
+	Multisite             MultisiteStrategy    `json:"multisite,omitempty" yaml:"multisite,omitempty" required:"false" enum:"host,host-by-locale,host-with-path,host-with-path-by-locale"`
+	FallbackLocale        string               `json:"fallbackLocale,omitempty" yaml:"fallbackLocale,omitempty" required:"false"`
+	IgnoreRequestPatterns []string             `json:"ignoreRequestPatterns,omitempty" yaml:"ignoreRequestPatterns,omitempty" required:"false"`
+	IgnoreRequestURIs     []string             `json:"ignoreRequestURIs,omitempty" yaml:"ignoreRequestURIs,omitempty" required:"false"`
+	SiteSkippers          *Skippers            `json:"siteSkippers,omitempty" yaml:"siteSkippers,omitempty" required:"false"`
+	PageSkippers          *Skippers            `json:"pageSkippers,omitempty" yaml:"pageSkippers,omitempty" required:"false"`
+	LoggerSkippers        *Skippers            `json:"loggerSkippers,omitempty" yaml:"loggerSkippers,omitempty" required:"false"`
+	CatchErrors           map[string][]int     `json:"catchErrors,omitempty" yaml:"catchErrors,omitempty" required:"false"`
+	Additional            map[string]string    `json:"additional,omitempty" yaml:"additional,omitempty" required:"false"`
+	PageRevisions         *RevisionRetention   `json:"pageRevisions,omitempty" yaml:"pageRevisions,omitempty" required:"false"`
+	Workflow              []WorkflowTransition `json:"workflow,omitempty" yaml:"workflow,omitempty" required:"false"`
 }
 
 func NewConfiguration() Configuration {
@@ -147,6 +149,14 @@ func (c Configuration) IgnoreURI(uri string) bool {
 	return false
 }
 
+// WorkflowTransitions returns the configured transitions or the default ones.
+func (c Configuration) WorkflowTransitions() []WorkflowTransition {
+	if len(c.Workflow) == 0 {
+		return DefaultWorkflowTransitions()
+	}
+	return c.Workflow
+}
+
 func (c Configuration) With(other Configuration) Configuration {
 	c.Debug = other.Debug
 
@@ -181,6 +191,15 @@ func (c Configuration) With(other Configuration) Configuration {
 		maps.Copy(c.Additional, other.Additional)
 	}
 
+	// an empty retention set explicitly means no limit, so it overrides the fallback as well
+	if other.PageRevisions != nil {
+		c.PageRevisions = other.PageRevisions
+	}
+
+	if len(other.Workflow) > 0 {
+		c.Workflow = other.Workflow
+	}
+
 	return c
 }
 
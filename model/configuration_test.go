@@ -0,0 +1,36 @@
+package model
+
+import "testing"
+
+func newTestConfiguration() Configuration {
+	cfg := NewConfiguration()
+	cfg.SiteSkippers, cfg.PageSkippers, cfg.LoggerSkippers = &Skippers{}, &Skippers{}, &Skippers{}
+	return cfg
+}
+
+func TestConfigurationWithPageRevisions(t *testing.T) {
+	fallback := newTestConfiguration()
+	fallback.PageRevisions = &RevisionRetention{MaxCount: 10}
+
+	tests := []struct {
+		name  string
+		other *RevisionRetention
+		want  RevisionRetention
+	}{
+		{name: "not set", want: RevisionRetention{MaxCount: 10}},
+		{name: "unlimited", other: &RevisionRetention{}, want: RevisionRetention{}},
+		{name: "max days", other: &RevisionRetention{MaxDays: 30}, want: RevisionRetention{MaxDays: 30}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := newTestConfiguration()
+			other.PageRevisions = tt.other
+
+			cfg := fallback.With(other)
+			if cfg.PageRevisions == nil || *cfg.PageRevisions != tt.want {
+				t.Errorf("page revisions = %v, want %v", cfg.PageRevisions, tt.want)
+			}
+		})
+	}
+}
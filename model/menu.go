@@ -7,14 +7,18 @@ import (
 )
 
 type Menu struct {
-	ID      int64     `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
-	NodeID  *int64    `json:"nodeID,omitempty" yaml:"nodeID,omitempty" required:"false"`
-	Name    string    `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
-	Handle  string    `json:"handle,omitempty" yaml:"handle,omitempty" required:"true"`
-	Enabled bool      `json:"enabled,omitempty" yaml:"enabled,omitempty" required:"true"`
-	Created time.Time `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
-	Updated time.Time `json:"updated,omitempty" yaml:"updated,omitempty" required:"true"`
-	Node    *Node     `json:"-" yaml:"-"`
+	ID       int64     `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	SiteID   *int64    `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"false"`
+	NodeID   *int64    `json:"nodeID,omitempty" yaml:"nodeID,omitempty" required:"false"`
+	Name     string    `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
+	Handle   string    `json:"handle,omitempty" yaml:"handle,omitempty" required:"true"`
+	Enabled  bool      `json:"enabled,omitempty" yaml:"enabled,omitempty" required:"true"`
+	Source   string    `json:"source,omitempty" yaml:"source,omitempty" required:"false"`
+	RootPage string    `json:"rootPage,omitempty" yaml:"rootPage,omitempty" required:"false"`
+	Depth    int       `json:"depth,omitempty" yaml:"depth,omitempty" required:"false"`
+	Created  time.Time `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
+	Updated  time.Time `json:"updated,omitempty" yaml:"updated,omitempty" required:"true"`
+	Node     *Node     `json:"-" yaml:"-"`
 }
 
 func (m Menu) GetID() int64 {
@@ -28,6 +32,11 @@ func (m Menu) String() string {
 	return m.Name
 }
 
+// IsGenerated reports whether the node tree of the menu is built by a menu source.
+func (m Menu) IsGenerated() bool {
+	return m.Source != ""
+}
+
 func (m Menu) WithFixedHandle() Menu {
 	if m.Handle == "" {
 		m.Handle = slug.Make(m.Name)
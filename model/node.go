@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,6 +12,7 @@ type Node struct {
 	Name               string            `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
 	Label              string            `json:"label,omitempty" yaml:"label,omitempty" required:"false"`
 	URI                string            `json:"uri,omitempty" yaml:"uri,omitempty" required:"false"`
+	PageID             *int64            `json:"pageID,omitempty" yaml:"pageID,omitempty" required:"false"`
 	Path               string            `json:"path,omitempty" yaml:"path,omitempty" required:"true"`
 	Level              int               `json:"level,omitempty" yaml:"level,omitempty" required:"false"`
 	Position           int               `json:"position,omitempty" yaml:"position,omitempty" required:"false"`
@@ -27,6 +29,7 @@ type Node struct {
 	Ancestor           bool              `json:"-" yaml:"-"`
 	Parent             *Node             `json:"-" yaml:"-"`
 	Menu               *Menu             `json:"-" yaml:"-"`
+	Page               *Page             `json:"-" yaml:"-"`
 	Children           []*Node           `json:"-" yaml:"-"`
 }
 
@@ -49,6 +52,19 @@ func (n Node) HasChildren() bool {
 	return len(n.Children) > 0
 }
 
+// PageAlias returns the page alias when the node URI follows the "_page_alias_" convention.
+func (n Node) PageAlias() string {
+	if strings.HasPrefix(n.URI, PageAliasPrefix) {
+		return n.URI
+	}
+	return ""
+}
+
+// IsPageLink reports whether the node links to a CMS page instead of a literal URI.
+func (n Node) IsPageLink() bool {
+	return n.PageID != nil || n.PageAlias() != ""
+}
+
 func (n Node) WithFixedPathAndLevel() Node {
 	if n.ParentID == 0 || n.Parent == nil {
 		n.ParentID = 0
@@ -16,6 +16,9 @@ const (
 	PageErrorInternal  = PageErrorPrefix + "internal"
 	PageError4xx       = PageErrorPrefix + "4xx"
 	PageError5xx       = PageErrorPrefix + "5xx"
+
+	// PageMetadataMenuExclude is the metadata key that excludes the page from generated menus.
+	PageMetadataMenuExclude = "menu_exclude"
 )
 
 type Page struct {
@@ -43,6 +46,7 @@ type Page struct {
 	Updated     time.Time         `json:"updated,omitempty" yaml:"updated,omitempty" required:"true"`
 	Published   *time.Time        `json:"published,omitempty" yaml:"published,omitempty" required:"false"`
 	Expired     *time.Time        `json:"expired,omitempty" yaml:"expired,omitempty" required:"false"`
+	State       WorkflowState     `json:"state,omitempty" yaml:"state,omitempty" required:"false" enum:"draft,in_review,approved,published,archived"`
 	Site        *Site             `json:"-" yaml:"-"`
 	Parent      *Page             `json:"-" yaml:"-"`
 	Children    []Page            `json:"-" yaml:"-"`
@@ -111,6 +115,14 @@ func (p Page) IsDynamic() bool {
 	return p.IsHybrid() && strings.ContainsAny(p.URL, ":{*")
 }
 
+func (p Page) IsMenuExcluded() bool {
+	switch strings.ToLower(p.Metadata[PageMetadataMenuExclude]) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
 func (p Page) WithFixedURL() Page {
 	if p.IsInternal() {
 		p.URL = ""
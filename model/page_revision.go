@@ -0,0 +1,35 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type PageRevision struct {
+	ID      int64     `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	PageID  int64     `json:"pageID,omitempty" yaml:"pageID,omitempty" required:"true"`
+	SiteID  int64     `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"true"`
+	Page    Page      `json:"page" yaml:"page" required:"true"`
+	Author  string    `json:"author,omitempty" yaml:"author,omitempty" required:"false"`
+	Message string    `json:"message,omitempty" yaml:"message,omitempty" required:"false"`
+	Created time.Time `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
+}
+
+func (r PageRevision) GetID() int64 {
+	return r.ID
+}
+
+func (r PageRevision) String() string {
+	return fmt.Sprintf("%s#%d", r.Page.Name, r.ID)
+}
+
+// RevisionRetention limits the stored revisions of a record.
+// The latest revision is always kept, zero values mean no limit.
+type RevisionRetention struct {
+	MaxCount int `json:"maxCount,omitempty" yaml:"maxCount,omitempty" required:"false" minimum:"0"`
+	MaxDays  int `json:"maxDays,omitempty" yaml:"maxDays,omitempty" required:"false" minimum:"0"`
+}
+
+func (r RevisionRetention) IsZero() bool {
+	return r.MaxCount <= 0 && r.MaxDays <= 0
+}
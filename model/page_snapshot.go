@@ -0,0 +1,92 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PageSnapshotTemplatePrefix prefixes the names of the templates frozen with the snapshots.
+const PageSnapshotTemplatePrefix = "_snapshot_"
+
+// PageSnapshot is the published state of the page served to the visitors.
+// A page has one snapshot at most, so the snapshot shares the id of the page,
+// and the lookup columns of the page are copied to query the snapshots the same way as the pages.
+type PageSnapshot struct {
+	ID        int64      `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	SiteID    int64      `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"true"`
+	ParentID  *int64     `json:"parentID,omitempty" yaml:"parentID,omitempty" required:"false"`
+	Pattern   string     `json:"pattern,omitempty" yaml:"pattern,omitempty" required:"true"`
+	Alias     string     `json:"alias,omitempty" yaml:"alias,omitempty" required:"false"`
+	URL       string     `json:"url,omitempty" yaml:"url,omitempty" required:"false"`
+	Page      Page       `json:"page" yaml:"page" required:"true"`
+	Template  *Template  `json:"template,omitempty" yaml:"template,omitempty" required:"false"`
+	Author    string     `json:"author,omitempty" yaml:"author,omitempty" required:"false"`
+	Published time.Time  `json:"published,omitempty" yaml:"published,omitempty" required:"true"`
+	Expired   *time.Time `json:"expired,omitempty" yaml:"expired,omitempty" required:"false"`
+}
+
+func NewPageSnapshot(page Page, template *Template, author string, published time.Time) PageSnapshot {
+	page.Site, page.Parent, page.Children = nil, nil, nil
+
+	return PageSnapshot{
+		ID:        page.ID,
+		SiteID:    page.SiteID,
+		ParentID:  page.ParentID,
+		Pattern:   page.Pattern,
+		Alias:     page.Alias,
+		URL:       page.URL,
+		Page:      page,
+		Template:  template,
+		Author:    author,
+		Published: published,
+		Expired:   page.Expired,
+	}
+}
+
+func (s PageSnapshot) GetID() int64 {
+	return s.ID
+}
+
+func (s PageSnapshot) String() string {
+	return s.Page.String()
+}
+
+// TemplateName returns the name of the frozen template, or the template of the page when it is not frozen.
+func (s PageSnapshot) TemplateName() string {
+	if s.Template == nil {
+		return s.Page.Template
+	}
+	return fmt.Sprintf("%s%d", PageSnapshotTemplatePrefix, s.ID)
+}
+
+// ServedPage returns the page rendered to the visitors.
+func (s PageSnapshot) ServedPage() Page {
+	page := s.Page
+	page.Template = s.TemplateName()
+	return page
+}
+
+// ServedTemplate returns the frozen template with the name used by the served page.
+func (s PageSnapshot) ServedTemplate() (Template, bool) {
+	if s.Template == nil {
+		return Template{}, false
+	}
+
+	t := *s.Template
+	t.ID = -s.ID
+	t.Name = s.TemplateName()
+	t.Enabled = true
+	t.Updated = s.Published
+	return t, true
+}
+
+// PageSnapshotID parses the id of the snapshot from the name of its frozen template.
+func PageSnapshotID(templateName string) (int64, bool) {
+	if !strings.HasPrefix(templateName, PageSnapshotTemplatePrefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(templateName, PageSnapshotTemplatePrefix), 10, 64)
+	return id, err == nil
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+type WorkflowState string
+
+const (
+	WorkflowDraft     WorkflowState = "draft"
+	WorkflowInReview  WorkflowState = "in_review"
+	WorkflowApproved  WorkflowState = "approved"
+	WorkflowPublished WorkflowState = "published"
+	WorkflowArchived  WorkflowState = "archived"
+)
+
+const (
+	TransitionSubmit  = "submit"
+	TransitionReject  = "reject"
+	TransitionApprove = "approve"
+	TransitionPublish = "publish"
+	TransitionArchive = "archive"
+	TransitionReopen  = "reopen"
+
+	// TransitionEdit is recorded when saving a page sends it back from review to draft.
+	TransitionEdit = "edit"
+)
+
+const (
+	RoleEditor    = "editor"
+	RoleReviewer  = "reviewer"
+	RolePublisher = "publisher"
+)
+
+func (s WorkflowState) IsZero() bool {
+	return s == ""
+}
+
+func (s WorkflowState) String() string {
+	return string(s)
+}
+
+// IsReviewed reports whether the content of the page in the state has been submitted for review,
+// so any change of the content requires a new review.
+func (s WorkflowState) IsReviewed() bool {
+	return s == WorkflowInReview || s == WorkflowApproved
+}
+
+type WorkflowTransition struct {
+	Name string          `json:"name" yaml:"name" required:"true"`
+	From []WorkflowState `json:"from" yaml:"from" required:"true" minItems:"1" enum:"draft,in_review,approved,published,archived"`
+	To   WorkflowState   `json:"to" yaml:"to" required:"true" enum:"draft,in_review,approved,published,archived"`
+
+	// Roles lists the roles allowed to apply the transition, any role is allowed when empty.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty" required:"false"`
+
+	// FourEyes requires the actor applying the transition to differ from the actor who submitted
+	// and the author who last edited the page. It applies to the public pages only.
+	FourEyes bool `json:"fourEyes,omitempty" yaml:"fourEyes,omitempty" required:"false"`
+}
+
+func (t WorkflowTransition) Allows(from WorkflowState) bool {
+	if from.IsZero() {
+		from = WorkflowDraft
+	}
+	return slices.Contains(t.From, from)
+}
+
+func (t WorkflowTransition) Granted(roles []string) bool {
+	if len(t.Roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if slices.Contains(t.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func DefaultWorkflowTransitions() []WorkflowTransition {
+	return []WorkflowTransition{
+		{Name: TransitionSubmit, From: []WorkflowState{WorkflowDraft}, To: WorkflowInReview, Roles: []string{RoleEditor}},
+		{Name: TransitionReject, From: []WorkflowState{WorkflowInReview, WorkflowApproved}, To: WorkflowDraft, Roles: []string{RoleReviewer}},
+		{Name: TransitionApprove, From: []WorkflowState{WorkflowInReview}, To: WorkflowApproved, Roles: []string{RoleReviewer}, FourEyes: true},
+		{Name: TransitionPublish, From: []WorkflowState{WorkflowApproved}, To: WorkflowPublished, Roles: []string{RolePublisher}},
+		{Name: TransitionArchive, From: []WorkflowState{WorkflowDraft, WorkflowApproved, WorkflowPublished}, To: WorkflowArchived, Roles: []string{RolePublisher}},
+		{Name: TransitionReopen, From: []WorkflowState{WorkflowPublished, WorkflowArchived}, To: WorkflowDraft, Roles: []string{RoleEditor}},
+	}
+}
+
+// PageTransition is the history record of a transition applied to a page.
+type PageTransition struct {
+	ID         int64         `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	PageID     int64         `json:"pageID,omitempty" yaml:"pageID,omitempty" required:"true"`
+	SiteID     int64         `json:"siteID,omitempty" yaml:"siteID,omitempty" required:"true"`
+	Transition string        `json:"transition" yaml:"transition" required:"true"`
+	From       WorkflowState `json:"from" yaml:"from" required:"true"`
+	To         WorkflowState `json:"to" yaml:"to" required:"true"`
+	Actor      string        `json:"actor,omitempty" yaml:"actor,omitempty" required:"false"`
+	Comment    string        `json:"comment,omitempty" yaml:"comment,omitempty" required:"false"`
+	Created    time.Time     `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
+}
+
+func (t PageTransition) GetID() int64 {
+	return t.ID
+}
+
+func (t PageTransition) String() string {
+	return fmt.Sprintf("%s: %s -> %s", t.Transition, t.From, t.To)
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type TemplateRevision struct {
+	ID         int64     `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	TemplateID int64     `json:"templateID,omitempty" yaml:"templateID,omitempty" required:"true"`
+	Name       string    `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
+	Content    string    `json:"content,omitempty" yaml:"content,omitempty" required:"false"`
+	Author     string    `json:"author,omitempty" yaml:"author,omitempty" required:"false"`
+	Message    string    `json:"message,omitempty" yaml:"message,omitempty" required:"false"`
+	Created    time.Time `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
+}
+
+func (r TemplateRevision) GetID() int64 {
+	return r.ID
+}
+
+func (r TemplateRevision) String() string {
+	return fmt.Sprintf("%s#%d", r.Name, r.ID)
+}
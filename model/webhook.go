@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook subscribes the URL to the events of the entities.
+// The empty filters match any entity, site and action.
+type Webhook struct {
+	ID       int64     `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	Name     string    `json:"name,omitempty" yaml:"name,omitempty" required:"true"`
+	URL      string    `json:"url,omitempty" yaml:"url,omitempty" required:"true"`
+	Secret   string    `json:"secret,omitempty" yaml:"secret,omitempty" required:"false"`
+	Entities []string  `json:"entities,omitempty" yaml:"entities,omitempty" required:"false"`
+	Actions  []string  `json:"actions,omitempty" yaml:"actions,omitempty" required:"false"`
+	SiteIDs  []int64   `json:"siteIDs,omitempty" yaml:"siteIDs,omitempty" required:"false"`
+	Enabled  bool      `json:"enabled,omitempty" yaml:"enabled,omitempty" required:"false"`
+	Created  time.Time `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
+	Updated  time.Time `json:"updated,omitempty" yaml:"updated,omitempty" required:"true"`
+}
+
+func (w Webhook) GetID() int64 {
+	return w.ID
+}
+
+func (w Webhook) String() string {
+	if w.Name == "" {
+		return "n/a"
+	}
+	return w.Name
+}
+
+// Matches reports whether the enabled webhook is subscribed to the event.
+// The events of the entities without a site, like the templates, match the site filter.
+func (w Webhook) Matches(entity, action string, siteID *int64) bool {
+	if !w.Enabled {
+		return false
+	}
+	if len(w.Entities) > 0 && !slices.Contains(w.Entities, entity) {
+		return false
+	}
+	if len(w.Actions) > 0 && !slices.Contains(w.Actions, action) {
+		return false
+	}
+	if len(w.SiteIDs) > 0 && siteID != nil && !slices.Contains(w.SiteIDs, *siteID) {
+		return false
+	}
+	return true
+}
+
+// WebhookDelivery is the log of the delivery of an event to a webhook with its last attempt.
+type WebhookDelivery struct {
+	ID             int64      `json:"id,omitempty" yaml:"id,omitempty" required:"true"`
+	WebhookID      int64      `json:"webhookID,omitempty" yaml:"webhookID,omitempty" required:"true"`
+	Event          string     `json:"event" yaml:"event" required:"true"`
+	Payload        string     `json:"payload" yaml:"payload" required:"true"`
+	Status         string     `json:"status" yaml:"status" required:"true" enum:"pending,succeeded,failed"`
+	Attempts       int        `json:"attempts" yaml:"attempts" required:"true"`
+	ResponseStatus int        `json:"responseStatus,omitempty" yaml:"responseStatus,omitempty" required:"false"`
+	ResponseBody   string     `json:"responseBody,omitempty" yaml:"responseBody,omitempty" required:"false"`
+	Error          string     `json:"error,omitempty" yaml:"error,omitempty" required:"false"`
+	NextAttempt    *time.Time `json:"nextAttempt,omitempty" yaml:"nextAttempt,omitempty" required:"false"`
+	Created        time.Time  `json:"created,omitempty" yaml:"created,omitempty" required:"true"`
+	Updated        time.Time  `json:"updated,omitempty" yaml:"updated,omitempty" required:"true"`
+}
+
+func (d WebhookDelivery) GetID() int64 {
+	return d.ID
+}
+
+func (d WebhookDelivery) String() string {
+	return fmt.Sprintf("%s#%d", d.Event, d.ID)
+}
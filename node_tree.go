@@ -0,0 +1,639 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gowool/cr"
+
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ NodeTree = (*DefaultNodeTree)(nil)
+
+type NodeDeleteOptions struct {
+	// WithMenu deletes the menus whose root node is deleted, otherwise the menus are detached from it.
+	WithMenu bool
+
+	// DryRun reports what would be deleted without deleting anything.
+	DryRun bool
+}
+
+type MenuDeleteOptions struct {
+	// WithNodes deletes the node tree of the menus.
+	WithNodes bool
+
+	// DryRun reports what would be deleted without deleting anything.
+	DryRun bool
+}
+
+type NodeDeleteReport struct {
+	Nodes         []int64 `json:"nodes,omitempty" yaml:"nodes,omitempty" required:"false"`
+	Menus         []int64 `json:"menus,omitempty" yaml:"menus,omitempty" required:"false"`
+	DetachedMenus []int64 `json:"detachedMenus,omitempty" yaml:"detachedMenus,omitempty" required:"false"`
+	DryRun        bool    `json:"dryRun,omitempty" yaml:"dryRun,omitempty" required:"false"`
+}
+
+type NodeTree interface {
+	// Create allocates the node ID, computes its path and level and saves the node.
+	Create(ctx context.Context, m *model.Node) error
+
+	// Update saves the node and rewrites the paths of its subtree when the parent has been changed.
+	Update(ctx context.Context, m *model.Node) error
+
+	// Move moves the node under the new parent (0 means a new root) at the position
+	// and keeps the positions of the new and old siblings dense.
+	Move(ctx context.Context, id, parentID int64, position int) error
+
+	// Reorder sets the positions of the sibling nodes in the order of ids.
+	Reorder(ctx context.Context, parentID int64, ids []int64) error
+
+	// Delete deletes the nodes together with their subtrees.
+	Delete(ctx context.Context, opts NodeDeleteOptions, ids ...int64) (NodeDeleteReport, error)
+
+	// DeleteMenus deletes the menus and optionally their node trees.
+	DeleteMenus(ctx context.Context, opts MenuDeleteOptions, ids ...int64) (NodeDeleteReport, error)
+
+	// CloneMenu copies the menu and its node tree to the site (nil means a global menu).
+	// An empty handle keeps the handle of the source menu.
+	// Node links to pages by ID are copied as is, links by page alias resolve against the target site.
+	CloneMenu(ctx context.Context, id int64, siteID *int64, handle string) (model.Menu, error)
+}
+
+type DefaultNodeTree struct {
+	nodeRepo repository.Node
+	menuRepo repository.Menu
+	sequence repository.SequenceNode
+	uow      repository.UnitOfWork
+	cache    Cache
+}
+
+func NewDefaultNodeTree(
+	nodeRepo repository.Node,
+	menuRepo repository.Menu,
+	sequence repository.SequenceNode,
+	uow repository.UnitOfWork,
+	cache Cache,
+) *DefaultNodeTree {
+	if nodeRepo == nil {
+		panic("node repository is not specified")
+	}
+	if menuRepo == nil {
+		panic("menu repository is not specified")
+	}
+	if sequence == nil {
+		panic("node sequence is not specified")
+	}
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+	return &DefaultNodeTree{
+		nodeRepo: nodeRepo,
+		menuRepo: menuRepo,
+		sequence: sequence,
+		uow:      uow,
+		cache:    cache,
+	}
+}
+
+func (t *DefaultNodeTree) Create(ctx context.Context, m *model.Node) error {
+	if m == nil {
+		return errors.New("node tree: create called with nil model")
+	}
+
+	m.Parent = nil
+	if m.ParentID != 0 {
+		parent, err := t.nodeRepo.FindByID(ctx, m.ParentID)
+		if err != nil {
+			return err
+		}
+		m.Parent = &parent
+	}
+
+	id, err := t.sequence.Create(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.ID = id
+	*m = m.WithFixedPathAndLevel()
+	m.Parent = nil
+
+	if err = t.nodeRepo.Create(ctx, m); err != nil {
+		return errors.Join(err, t.sequence.Delete(ctx, id))
+	}
+
+	t.purge(ctx, nil, m.ParentID)
+	return nil
+}
+
+func (t *DefaultNodeTree) Update(ctx context.Context, m *model.Node) error {
+	if m == nil {
+		return errors.New("node tree: update called with nil model")
+	}
+
+	old, err := t.nodeRepo.FindByID(ctx, m.ID)
+	if err != nil {
+		return err
+	}
+
+	if old.ParentID == m.ParentID {
+		return t.save(ctx, []model.Node{*m}, old.ParentID)
+	}
+
+	data, err := t.prepare(ctx, *m)
+	if err != nil {
+		return err
+	}
+	*m = data[0]
+
+	return t.save(ctx, data, old.ParentID, m.ParentID)
+}
+
+func (t *DefaultNodeTree) Move(ctx context.Context, id, parentID int64, position int) error {
+	m, err := t.nodeRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	oldParentID := m.ParentID
+	m.ParentID = parentID
+
+	data, err := t.prepare(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	// the roots are the siblings of the root nodes
+	siblings, err := t.siblings(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	siblings = slices.DeleteFunc(siblings, func(item model.Node) bool {
+		return item.ID == id
+	})
+
+	position = max(0, min(position, len(siblings)))
+	siblings = slices.Insert(siblings, position, data[0])
+
+	data = append(data, dense(siblings, id, &data[0])...)
+
+	if oldParentID != parentID {
+		siblings, err := t.siblings(ctx, oldParentID)
+		if err != nil {
+			return err
+		}
+		siblings = slices.DeleteFunc(siblings, func(item model.Node) bool {
+			return item.ID == id
+		})
+
+		data = append(data, dense(siblings, id, nil)...)
+	}
+
+	return t.save(ctx, data, oldParentID, parentID)
+}
+
+func (t *DefaultNodeTree) Reorder(ctx context.Context, parentID int64, ids []int64) error {
+	siblings, err := t.siblings(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		if !slices.ContainsFunc(siblings, func(item model.Node) bool { return item.ID == id }) {
+			return fmt.Errorf("node tree: node %d is not a child of the node %d: %w", id, parentID, ErrNodeNotFound)
+		}
+		index[id] = i
+	}
+
+	// nodes missing in ids keep their relative order after the reordered ones
+	slices.SortStableFunc(siblings, func(a, b model.Node) int {
+		i, aok := index[a.ID]
+		j, bok := index[b.ID]
+		switch {
+		case aok && bok:
+			return i - j
+		case aok:
+			return -1
+		case bok:
+			return 1
+		}
+		return a.Position - b.Position
+	})
+
+	return t.save(ctx, dense(siblings, 0, nil), parentID)
+}
+
+func (t *DefaultNodeTree) Delete(ctx context.Context, opts NodeDeleteOptions, ids ...int64) (NodeDeleteReport, error) {
+	report := NodeDeleteReport{DryRun: opts.DryRun}
+
+	nodes, err := t.subtrees(ctx, ids...)
+	if err != nil {
+		return report, err
+	}
+	report.Nodes = internal.Map(nodes, func(item model.Node) int64 { return item.ID })
+
+	menus, err := t.menusByNodes(ctx, report.Nodes)
+	if err != nil {
+		return report, err
+	}
+
+	for i := range menus {
+		if opts.WithMenu {
+			report.Menus = append(report.Menus, menus[i].ID)
+		} else {
+			report.DetachedMenus = append(report.DetachedMenus, menus[i].ID)
+			menus[i].NodeID = nil
+		}
+	}
+
+	if opts.DryRun || len(report.Nodes) == 0 {
+		return report, nil
+	}
+
+	if err = t.uow.Do(ctx, func(ctx context.Context) error {
+		return t.delete(ctx, report, menus, opts.WithMenu)
+	}); err != nil {
+		return report, err
+	}
+
+	t.purge(ctx, report.Menus, parentIDs(nodes)...)
+	t.purge(ctx, report.DetachedMenus, report.Nodes...)
+	return report, nil
+}
+
+func (t *DefaultNodeTree) DeleteMenus(ctx context.Context, opts MenuDeleteOptions, ids ...int64) (NodeDeleteReport, error) {
+	report := NodeDeleteReport{DryRun: opts.DryRun, Menus: ids}
+
+	var nodes []model.Node
+	if opts.WithNodes {
+		var roots []int64
+		for _, id := range ids {
+			menu, err := t.menuRepo.FindByID(ctx, id)
+			if err != nil {
+				return report, err
+			}
+			if menu.NodeID != nil {
+				roots = append(roots, *menu.NodeID)
+			}
+		}
+
+		var err error
+		if nodes, err = t.subtrees(ctx, roots...); err != nil {
+			return report, err
+		}
+		report.Nodes = internal.Map(nodes, func(item model.Node) int64 { return item.ID })
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if err := t.uow.Do(ctx, func(ctx context.Context) error {
+		return t.delete(ctx, report, nil, true)
+	}); err != nil {
+		return report, err
+	}
+
+	t.purge(ctx, report.Menus, parentIDs(nodes)...)
+	t.purge(ctx, nil, report.Nodes...)
+	return report, nil
+}
+
+func (t *DefaultNodeTree) CloneMenu(ctx context.Context, id int64, siteID *int64, handle string) (model.Menu, error) {
+	menu, err := t.menuRepo.FindByID(ctx, id)
+	if err != nil {
+		return model.Menu{}, err
+	}
+
+	if handle == "" {
+		handle = menu.Handle
+	}
+
+	if err = ValidateMenuHandle(ctx, t.menuRepo, model.Menu{SiteID: siteID, Handle: handle}); err != nil {
+		return model.Menu{}, err
+	}
+
+	var nodes []model.Node
+	if menu.NodeID != nil {
+		data, err := t.nodeRepo.FindWithChildren(ctx, *menu.NodeID)
+		if err != nil {
+			return model.Menu{}, err
+		}
+		if root := BuildTree(data, *menu.NodeID); root != nil {
+			if nodes, err = t.cloneNodes(ctx, root); err != nil {
+				return model.Menu{}, err
+			}
+		}
+	}
+
+	clone := menu
+	clone.ID = 0
+	clone.SiteID = siteID
+	clone.Handle = handle
+	clone.NodeID = nil
+	clone.Node = nil
+	if len(nodes) > 0 {
+		clone.NodeID = &nodes[0].ID
+	}
+
+	if err = t.uow.Do(ctx, func(ctx context.Context) error {
+		for i := range nodes {
+			if err := t.nodeRepo.Create(ctx, &nodes[i]); err != nil {
+				return err
+			}
+		}
+		return t.menuRepo.Create(ctx, &clone)
+	}); err != nil {
+		ids := internal.Map(nodes, func(item model.Node) int64 { return item.ID })
+		return model.Menu{}, errors.Join(err, t.sequence.Delete(ctx, ids...))
+	}
+	return clone, nil
+}
+
+// cloneNodes allocates new IDs for the subtree and returns its copy, parents before children.
+func (t *DefaultNodeTree) cloneNodes(ctx context.Context, root *model.Node) (nodes []model.Node, err error) {
+	defer func() {
+		if err != nil && len(nodes) > 0 {
+			ids := internal.Map(nodes, func(item model.Node) int64 { return item.ID })
+			err = errors.Join(err, t.sequence.Delete(ctx, ids...))
+		}
+	}()
+
+	var walk func(node *model.Node, parent *model.Node) error
+	walk = func(node *model.Node, parent *model.Node) error {
+		id, err := t.sequence.Create(ctx)
+		if err != nil {
+			return err
+		}
+
+		clone := *node
+		clone.ID = id
+		clone.ParentID = 0
+		clone.Parent = parent
+		clone.Children = nil
+		clone.Menu = nil
+		clone.Page = nil
+		clone.Current = false
+		clone.Ancestor = false
+		if parent != nil {
+			clone.ParentID = parent.ID
+		}
+		clone = clone.WithFixedPathAndLevel()
+
+		nodes = append(nodes, clone)
+
+		for _, child := range node.Children {
+			if err = walk(child, &clone); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err = walk(root, nil); err != nil {
+		return
+	}
+
+	for i := range nodes {
+		nodes[i].Parent = nil
+	}
+	return
+}
+
+func (t *DefaultNodeTree) delete(ctx context.Context, report NodeDeleteReport, detached []model.Menu, withMenu bool) error {
+	if withMenu && len(report.Menus) > 0 {
+		if err := t.menuRepo.Delete(ctx, report.Menus...); err != nil {
+			return err
+		}
+	}
+
+	if !withMenu {
+		for i := range detached {
+			if err := t.menuRepo.Update(ctx, &detached[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(report.Nodes) > 0 {
+		// children first, so a foreign key on the parent is never violated
+		nodes := slices.Clone(report.Nodes)
+		slices.Reverse(nodes)
+
+		if err := t.nodeRepo.Delete(ctx, nodes...); err != nil {
+			return err
+		}
+		if err := t.sequence.Delete(ctx, nodes...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subtrees returns the nodes with all their descendants ordered by the level.
+func (t *DefaultNodeTree) subtrees(ctx context.Context, ids ...int64) ([]model.Node, error) {
+	seen := make(map[int64]struct{})
+
+	var nodes []model.Node
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+
+		data, err := t.nodeRepo.FindWithChildren(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.ContainsFunc(data, func(item model.Node) bool { return item.ID == id }) {
+			return nil, fmt.Errorf("node tree: node %d: %w", id, ErrNodeNotFound)
+		}
+
+		for _, n := range data {
+			if _, ok := seen[n.ID]; !ok {
+				seen[n.ID] = struct{}{}
+				nodes = append(nodes, n)
+			}
+		}
+	}
+
+	slices.SortStableFunc(nodes, func(a, b model.Node) int {
+		return a.Level - b.Level
+	})
+	return nodes, nil
+}
+
+func (t *DefaultNodeTree) menusByNodes(ctx context.Context, ids []int64) ([]model.Menu, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return t.menuRepo.Find(ctx, cr.New().SetFilter(cr.Filter{
+		Conditions: []any{cr.Condition{Column: "node_id", Operator: cr.OpIN, Value: ids}},
+	}))
+}
+
+func (t *DefaultNodeTree) purge(ctx context.Context, menuIDs []int64, nodeIDs ...int64) {
+	if t.cache == nil {
+		return
+	}
+
+	_ = t.cache.DelByTag(ctx, MenuFragmentNodesCacheTag())
+	for _, id := range menuIDs {
+		_ = t.cache.DelByTag(ctx, CacheTag(CachePrefixMenu, id))
+	}
+	for _, id := range nodeIDs {
+		if id != 0 {
+			_ = t.cache.DelByTag(ctx, CacheTag(CachePrefixNode, id))
+		}
+	}
+}
+
+// prepare checks that the node is not moved under its own subtree and returns
+// the node followed by its descendants with the rewritten paths and levels.
+func (t *DefaultNodeTree) prepare(ctx context.Context, m model.Node) ([]model.Node, error) {
+	m.Parent = nil
+	if m.ParentID != 0 {
+		parent, err := t.nodeRepo.FindByID(ctx, m.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(strings.Split(parent.Path, "/"), strconv.FormatInt(m.ID, 10)) {
+			return nil, fmt.Errorf("node tree: node %d cannot be moved under itself or its descendant: %w", m.ID, ErrNodeTreeCycle)
+		}
+		m.Parent = &parent
+	}
+
+	nodes, err := t.nodeRepo.FindWithChildren(ctx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes = slices.DeleteFunc(nodes, func(item model.Node) bool {
+		return item.ID == m.ID
+	})
+	nodes = append([]model.Node{m}, nodes...)
+
+	root := BuildTree(nodes, m.ID)
+	if root == nil {
+		return nil, fmt.Errorf("node tree: node %d: %w", m.ID, ErrNodeNotFound)
+	}
+	root.Parent = m.Parent
+
+	data := make([]model.Node, 0, len(nodes))
+
+	var fix func(*model.Node)
+	fix = func(n *model.Node) {
+		*n = n.WithFixedPathAndLevel()
+
+		item := *n
+		item.Parent = nil
+		item.Children = nil
+		data = append(data, item)
+
+		for _, child := range n.Children {
+			child.Parent = n
+			fix(child)
+		}
+	}
+	fix(root)
+
+	return data, nil
+}
+
+// siblings returns the children of the parent, the root nodes for the zero parent.
+func (t *DefaultNodeTree) siblings(ctx context.Context, parentID int64) ([]model.Node, error) {
+	filter := cr.Filter{
+		Conditions: []any{cr.Condition{Column: "parent_id", Operator: cr.OpEqual, Value: parentID}},
+	}
+	if parentID == 0 {
+		filter = cr.Filter{
+			Operator: cr.OpOR,
+			Conditions: []any{
+				cr.Condition{Column: "parent_id", Operator: cr.OpIS, Value: nil},
+				cr.Condition{Column: "parent_id", Operator: cr.OpEqual, Value: 0},
+			},
+		}
+	}
+
+	data, err := t.nodeRepo.Find(ctx, cr.New().
+		SetFilter(filter).
+		SetSortBy(cr.Sort{Column: "position", Order: "ASC"}, cr.Sort{Column: "id", Order: "ASC"}))
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(data, func(a, b model.Node) int {
+		return a.Position - b.Position
+	})
+	return data, nil
+}
+
+func (t *DefaultNodeTree) save(ctx context.Context, data []model.Node, parentIDs ...int64) error {
+	if err := t.uow.Do(ctx, func(ctx context.Context) error {
+		for i := range data {
+			if err := t.nodeRepo.Update(ctx, &data[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	t.purge(ctx, nil, append(parentIDs, internal.Map(data, func(item model.Node) int64 { return item.ID })...)...)
+	return nil
+}
+
+func parentIDs(nodes []model.Node) []int64 {
+	return internal.Unique(internal.Map(nodes, func(item model.Node) int64 { return item.ParentID }))
+}
+
+// dense renumbers the positions of the nodes and returns those that have been changed.
+// The node with the id is renumbered in place of target instead.
+func dense(nodes []model.Node, id int64, target *model.Node) []model.Node {
+	var changed []model.Node
+	for i := range nodes {
+		if target != nil && nodes[i].ID == id {
+			target.Position = i
+			continue
+		}
+		if nodes[i].Position != i {
+			nodes[i].Position = i
+			changed = append(changed, nodes[i])
+		}
+	}
+	return changed
+}
+
+// ValidateMenuHandle checks that no other menu of the site (nil means the global menus) uses the handle of the menu.
+func ValidateMenuHandle(ctx context.Context, menuRepo repository.Menu, m model.Menu) error {
+	if m.Handle == "" {
+		return nil
+	}
+
+	existing, err := menuRepo.FindByHandle(ctx, m.SiteID, m.Handle)
+	if err != nil {
+		if IsOneOfNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID == m.ID {
+		return nil
+	}
+
+	return &ValidationError{Errors: []FieldError{{
+		Field:   "handle",
+		Value:   m.Handle,
+		Message: fmt.Sprintf("already used by menu %d (%s)", existing.ID, existing.String()),
+	}}}
+}
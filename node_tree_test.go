@@ -0,0 +1,208 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func newTestNodeTree(t *testing.T) (*DefaultNodeTree, memory.NodeRepository, memory.MenuRepository) {
+	t.Helper()
+
+	nodeRepo := memory.NewNodeRepository(ErrNodeNotFound)
+	menuRepo := memory.NewMenuRepository(ErrMenuNotFound)
+	return NewDefaultNodeTree(nodeRepo, menuRepo, &memory.Sequence{}, &memory.UnitOfWork{}, memory.NewCache()), nodeRepo, menuRepo
+}
+
+func createTestNode(t *testing.T, tree NodeTree, name string, parentID int64) model.Node {
+	t.Helper()
+
+	m := model.Node{Name: name, ParentID: parentID}
+	if err := tree.Create(context.Background(), &m); err != nil {
+		t.Fatalf("create node %q: %v", name, err)
+	}
+	return m
+}
+
+func assertNodePath(t *testing.T, repo memory.NodeRepository, id int64, path string, level int) {
+	t.Helper()
+
+	m, err := repo.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Path != path || m.Level != level {
+		t.Errorf("node %d = %q at level %d, want %q at level %d", id, m.Path, m.Level, path, level)
+	}
+}
+
+func TestNodeTreeCreateMaterializesPath(t *testing.T) {
+	tree, repo, _ := newTestNodeTree(t)
+
+	root := createTestNode(t, tree, "root", 0)
+	child := createTestNode(t, tree, "child", root.ID)
+	leaf := createTestNode(t, tree, "leaf", child.ID)
+
+	assertNodePath(t, repo, root.ID, "1", 0)
+	assertNodePath(t, repo, child.ID, "1/2", 1)
+	assertNodePath(t, repo, leaf.ID, "1/2/3", 2)
+}
+
+func TestNodeTreeMoveRewritesSubtree(t *testing.T) {
+	ctx := context.Background()
+	tree, repo, _ := newTestNodeTree(t)
+
+	root := createTestNode(t, tree, "root", 0)
+	a := createTestNode(t, tree, "a", root.ID)
+	b := createTestNode(t, tree, "b", root.ID)
+	leaf := createTestNode(t, tree, "leaf", a.ID)
+
+	if err := tree.Move(ctx, a.ID, b.ID, 0); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+
+	assertNodePath(t, repo, a.ID, "1/3/2", 2)
+	assertNodePath(t, repo, leaf.ID, "1/3/2/4", 3)
+
+	if err := tree.Move(ctx, b.ID, 0, 0); err != nil {
+		t.Fatalf("move to root: %v", err)
+	}
+
+	assertNodePath(t, repo, b.ID, "3", 0)
+	assertNodePath(t, repo, leaf.ID, "3/2/4", 2)
+}
+
+func TestNodeTreeMoveToRootKeepsPositionsDense(t *testing.T) {
+	ctx := context.Background()
+	tree, repo, _ := newTestNodeTree(t)
+
+	main := createTestNode(t, tree, "main", 0)
+	footer := createTestNode(t, tree, "footer", 0)
+	child := createTestNode(t, tree, "child", main.ID)
+
+	if err := tree.Move(ctx, child.ID, 0, 1); err != nil {
+		t.Fatalf("move to root: %v", err)
+	}
+
+	for id, want := range map[int64]int{main.ID: 0, child.ID: 1, footer.ID: 2} {
+		m, _ := repo.FindByID(ctx, id)
+		if m.Position != want {
+			t.Errorf("root %d position = %d, want %d", id, m.Position, want)
+		}
+	}
+
+	if err := tree.Move(ctx, main.ID, footer.ID, 0); err != nil {
+		t.Fatalf("move from root: %v", err)
+	}
+
+	for id, want := range map[int64]int{child.ID: 0, footer.ID: 1} {
+		m, _ := repo.FindByID(ctx, id)
+		if m.Position != want {
+			t.Errorf("root %d position = %d, want %d", id, m.Position, want)
+		}
+	}
+}
+
+func TestNodeTreeMoveRejectsCycle(t *testing.T) {
+	tree, _, _ := newTestNodeTree(t)
+
+	root := createTestNode(t, tree, "root", 0)
+	child := createTestNode(t, tree, "child", root.ID)
+
+	if err := tree.Move(context.Background(), root.ID, child.ID, 0); !errors.Is(err, ErrNodeTreeCycle) {
+		t.Fatalf("err = %v, want ErrNodeTreeCycle", err)
+	}
+}
+
+func TestNodeTreeReorder(t *testing.T) {
+	ctx := context.Background()
+	tree, repo, _ := newTestNodeTree(t)
+
+	root := createTestNode(t, tree, "root", 0)
+	a := createTestNode(t, tree, "a", root.ID)
+	b := createTestNode(t, tree, "b", root.ID)
+	c := createTestNode(t, tree, "c", root.ID)
+
+	if err := tree.Reorder(ctx, root.ID, []int64{c.ID, a.ID}); err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+
+	for id, want := range map[int64]int{c.ID: 0, a.ID: 1, b.ID: 2} {
+		m, _ := repo.FindByID(ctx, id)
+		if m.Position != want {
+			t.Errorf("node %d position = %d, want %d", id, m.Position, want)
+		}
+	}
+}
+
+func TestNodeTreeDeleteCascades(t *testing.T) {
+	ctx := context.Background()
+	tree, repo, menuRepo := newTestNodeTree(t)
+
+	root := createTestNode(t, tree, "root", 0)
+	a := createTestNode(t, tree, "a", root.ID)
+	leaf := createTestNode(t, tree, "leaf", a.ID)
+	b := createTestNode(t, tree, "b", root.ID)
+
+	menu := model.Menu{Name: "Main", Handle: "main", NodeID: &a.ID}
+	if err := menuRepo.Create(ctx, &menu); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := tree.Delete(ctx, NodeDeleteOptions{DryRun: true}, a.ID)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !report.DryRun || len(report.Nodes) != 2 || repo.Len() != 4 {
+		t.Errorf("dry run report = %+v with %d nodes left, want nothing deleted", report, repo.Len())
+	}
+
+	report, err = tree.Delete(ctx, NodeDeleteOptions{}, a.ID)
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if !slices.Equal(report.Nodes, []int64{a.ID, leaf.ID}) || !slices.Equal(report.DetachedMenus, []int64{menu.ID}) {
+		t.Errorf("report = %+v", report)
+	}
+	if _, err = repo.FindByID(ctx, leaf.ID); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("leaf err = %v, want ErrNodeNotFound", err)
+	}
+	if _, err = repo.FindByID(ctx, b.ID); err != nil {
+		t.Errorf("sibling deleted: %v", err)
+	}
+
+	m, _ := menuRepo.FindByID(ctx, menu.ID)
+	if m.NodeID != nil {
+		t.Errorf("menu node = %d, want detached", *m.NodeID)
+	}
+}
+
+func TestValidateMenuHandle(t *testing.T) {
+	ctx := context.Background()
+	_, _, menuRepo := newTestNodeTree(t)
+
+	siteID := int64(1)
+	main := model.Menu{SiteID: &siteID, Name: "Main", Handle: "main"}
+	if err := menuRepo.Create(ctx, &main); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateMenuHandle(ctx, menuRepo, main); err != nil {
+		t.Errorf("the menu itself: %v", err)
+	}
+
+	var validationErr *ValidationError
+	if err := ValidateMenuHandle(ctx, menuRepo, model.Menu{SiteID: &siteID, Handle: "main"}); !errors.As(err, &validationErr) {
+		t.Errorf("duplicate handle: got %v, want a validation error", err)
+	}
+
+	otherID := int64(2)
+	if err := ValidateMenuHandle(ctx, menuRepo, model.Menu{SiteID: &otherID, Handle: "main"}); err != nil {
+		t.Errorf("another site: %v", err)
+	}
+}
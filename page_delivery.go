@@ -0,0 +1,221 @@
+package pages
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+	"github.com/gowool/pages/seo"
+)
+
+var (
+	_ PageDelivery    = (*DefaultPageDelivery)(nil)
+	_ PreviewVerifier = (*HMACPreviewVerifier)(nil)
+)
+
+type PageDeliveryRequest struct {
+	Scheme         string
+	Host           string
+	Path           string
+	AcceptLanguage string
+
+	// Preview ignores the publish windows of the page, like an editor does.
+	Preview bool
+}
+
+type PageDeliveryResult struct {
+	Site *model.Site
+	Page *model.Page
+
+	// Parents of the page from the root.
+	Parents []model.Page
+
+	SEO      seo.SEO
+	Redirect *RedirectError
+}
+
+// PageDelivery resolves a url to the site and page for decoupled front-ends.
+type PageDelivery interface {
+	Deliver(ctx context.Context, req PageDeliveryRequest) (PageDeliveryResult, error)
+}
+
+type DefaultPageDelivery struct {
+	siteSelector SiteSelector
+	pageRepo     repository.Page
+}
+
+func NewDefaultPageDelivery(siteSelector SiteSelector, pageRepo repository.Page) *DefaultPageDelivery {
+	if siteSelector == nil {
+		panic("site selector is not specified")
+	}
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	return &DefaultPageDelivery{
+		siteSelector: siteSelector,
+		pageRepo:     pageRepo,
+	}
+}
+
+func (d *DefaultPageDelivery) Deliver(ctx context.Context, req PageDeliveryRequest) (PageDeliveryResult, error) {
+	var result PageDeliveryResult
+
+	var now time.Time
+	if req.Preview {
+		ctx = WithEditor(ctx, true)
+	} else {
+		now = time.Now().UTC()
+	}
+
+	r, err := deliveryRequest(ctx, req)
+	if err != nil {
+		return result, err
+	}
+
+	site, urlPath, err := d.siteSelector.Retrieve(r)
+	if err != nil {
+		var redirect RedirectError
+		if errors.As(err, &redirect) {
+			result.Redirect = &redirect
+			return result, nil
+		}
+		return result, err
+	}
+	if site == nil {
+		return result, fmt.Errorf("page delivery: %w", ErrSiteNotFound)
+	}
+	result.Site = site
+
+	page, err := d.pageRepo.FindByURL(ctx, site.ID, urlPath, now)
+	if err != nil {
+		return result, err
+	}
+	if page.IsInternal() || (!now.IsZero() && !page.IsEnabled(now)) {
+		return result, fmt.Errorf("page delivery: %w", ErrPageNotFound)
+	}
+	page.Site = site
+	result.Page = &page
+
+	if result.Parents, err = d.parents(ctx, page, now); err != nil {
+		return result, err
+	}
+
+	if location := page.Headers[echo.HeaderLocation]; location != "" && page.Status >= 300 && page.Status < 400 {
+		result.Redirect = &RedirectError{Status: page.Status, URL: location}
+	}
+
+	result.SEO = seo.NewSEO().Site(site).Page(&page)
+	return result, nil
+}
+
+// parents returns the ancestors of the page up to the first one hidden at now, zero now shows all of them.
+func (d *DefaultPageDelivery) parents(ctx context.Context, page model.Page, now time.Time) ([]model.Page, error) {
+	var parents []model.Page
+	visited := map[int64]struct{}{page.ID: {}}
+
+	for id := page.ParentID; id != nil; {
+		if _, ok := visited[*id]; ok {
+			return nil, fmt.Errorf("page delivery: page %d is visited twice: %w", *id, ErrPageTreeCycle)
+		}
+		visited[*id] = struct{}{}
+
+		parent, err := d.pageRepo.FindByID(ctx, *id)
+		if err != nil {
+			if IsOneOfNotFound(err) {
+				break
+			}
+			return nil, err
+		}
+		if !now.IsZero() && !parent.IsEnabled(now) {
+			break
+		}
+		parents = append(parents, parent)
+		id = parent.ParentID
+	}
+
+	slices.Reverse(parents)
+	return parents, nil
+}
+
+// deliveryRequest builds the request the site selector would receive from a browser.
+func deliveryRequest(ctx context.Context, req PageDeliveryRequest) (*http.Request, error) {
+	scheme := req.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	path := req.Path
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%s://%s%s", scheme, req.Host, path))
+	if err != nil {
+		return nil, fmt.Errorf("page delivery: invalid url: %w", err)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set(echo.HeaderXForwardedProto, scheme)
+	if req.AcceptLanguage != "" {
+		r.Header.Set("Accept-Language", req.AcceptLanguage)
+	}
+	return r, nil
+}
+
+// PreviewVerifier checks the tokens which let the front-ends see the unpublished pages.
+type PreviewVerifier interface {
+	Verify(ctx context.Context, token string) error
+}
+
+// HMACPreviewVerifier verifies the tokens in the "expires.signature" format,
+// where expires is the unix time and signature is its hex encoded HMAC-SHA256.
+type HMACPreviewVerifier struct {
+	secret []byte
+}
+
+func NewHMACPreviewVerifier(secret []byte) *HMACPreviewVerifier {
+	if len(secret) == 0 {
+		panic("preview secret is not specified")
+	}
+	return &HMACPreviewVerifier{secret: secret}
+}
+
+func (v *HMACPreviewVerifier) Sign(expires time.Time) string {
+	payload := strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + v.signature(payload)
+}
+
+func (v *HMACPreviewVerifier) Verify(_ context.Context, token string) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(v.signature(payload))) {
+		return ErrInvalidPreviewToken
+	}
+
+	expires, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return ErrInvalidPreviewToken
+	}
+	return nil
+}
+
+func (v *HMACPreviewVerifier) signature(payload string) string {
+	mac := hmac.New(sha256.New, v.secret)
+	_, _ = mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package pages
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func TestPageDeliveryParentsStopAtHiddenAncestor(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewPageRepository(ErrPageNotFound)
+	tree := NewDefaultPageTree(repo, &memory.UnitOfWork{}, nil)
+	d := &DefaultPageDelivery{pageRepo: repo}
+
+	now := time.Now().UTC()
+	published := internal.Ptr(now.Add(-time.Hour))
+	expired := internal.Ptr(now.Add(-time.Minute))
+	root := createTestPage(t, tree, model.Page{Name: "Home", Published: published})
+	archive := createTestPage(t, tree, model.Page{Name: "Archive", ParentID: &root.ID, Published: published, Expired: expired})
+	post := createTestPage(t, tree, model.Page{Name: "Post", ParentID: &archive.ID, Published: published})
+
+	parents, err := d.parents(ctx, post, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 0 {
+		t.Errorf("parents = %v, want none above the expired one", parents)
+	}
+
+	if parents, err = d.parents(ctx, post, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 2 || parents[0].ID != root.ID {
+		t.Errorf("preview parents = %v, want the root and the archive", parents)
+	}
+}
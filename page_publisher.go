@@ -0,0 +1,131 @@
+package pages
+
+import (
+	"context"
+	"time"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ PagePublisher = (*DefaultPagePublisher)(nil)
+
+type PagePublisher interface {
+	// PublishPage replaces the snapshot of the page with its current draft,
+	// the snapshot is published at the publication time of the page or now when it is not set.
+	// The template of the page is frozen with the snapshot when withTemplate is set.
+	// It does not check the workflow, PageWorkflow publishes the pages through it.
+	PublishPage(ctx context.Context, id int64, withTemplate bool) (model.PageSnapshot, error)
+
+	// Unpublish removes the snapshot of the page, so it is not served to the visitors anymore.
+	Unpublish(ctx context.Context, id int64) error
+}
+
+type DefaultPagePublisher struct {
+	pageRepo     repository.Page
+	templateRepo repository.Template
+	snapshotRepo repository.PageSnapshot
+	uow          repository.UnitOfWork
+	cache        Cache
+}
+
+func NewDefaultPagePublisher(
+	pageRepo repository.Page,
+	templateRepo repository.Template,
+	snapshotRepo repository.PageSnapshot,
+	uow repository.UnitOfWork,
+	cache Cache,
+) *DefaultPagePublisher {
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	if templateRepo == nil {
+		panic("template repository is not specified")
+	}
+	if snapshotRepo == nil {
+		panic("page snapshot repository is not specified")
+	}
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+	return &DefaultPagePublisher{
+		pageRepo:     pageRepo,
+		templateRepo: templateRepo,
+		snapshotRepo: snapshotRepo,
+		uow:          uow,
+		cache:        cache,
+	}
+}
+
+func (p *DefaultPagePublisher) PublishPage(ctx context.Context, id int64, withTemplate bool) (model.PageSnapshot, error) {
+	page, err := p.pageRepo.FindByID(WithEditor(ctx, true), id)
+	if err != nil {
+		return model.PageSnapshot{}, err
+	}
+
+	published := time.Now().UTC()
+	if page.Published != nil {
+		published = page.Published.UTC()
+	}
+
+	var snapshot model.PageSnapshot
+	if err = p.uow.Do(ctx, func(ctx context.Context) error {
+		snapshot, err = p.publish(ctx, page, withTemplate, published)
+		return err
+	}); err != nil {
+		return model.PageSnapshot{}, err
+	}
+
+	p.purge(ctx, page.SiteID, page.ID)
+	return snapshot, nil
+}
+
+func (p *DefaultPagePublisher) Unpublish(ctx context.Context, id int64) error {
+	snapshot, err := p.snapshotRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = p.snapshotRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	p.purge(ctx, snapshot.SiteID, id)
+	return nil
+}
+
+func (p *DefaultPagePublisher) publish(ctx context.Context, page model.Page, withTemplate bool, published time.Time) (model.PageSnapshot, error) {
+	var tpl *model.Template
+	if withTemplate && page.Template != "" {
+		t, err := p.templateRepo.FindByName(ctx, page.Template)
+		if err != nil {
+			return model.PageSnapshot{}, err
+		}
+		tpl = &t
+	}
+
+	if _, err := p.snapshotRepo.FindByID(ctx, page.ID); err == nil {
+		if err = p.snapshotRepo.Delete(ctx, page.ID); err != nil {
+			return model.PageSnapshot{}, err
+		}
+	} else if !IsOneOfNotFound(err) {
+		return model.PageSnapshot{}, err
+	}
+
+	snapshot := model.NewPageSnapshot(page, tpl, CtxActor(ctx), published)
+	if err := p.snapshotRepo.Create(ctx, &snapshot); err != nil {
+		return model.PageSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+func (p *DefaultPagePublisher) purge(ctx context.Context, siteID int64, ids ...int64) {
+	if p.cache == nil {
+		return
+	}
+
+	for _, id := range ids {
+		_ = p.cache.DelByTag(ctx, CacheTag(CachePrefixPage, id))
+	}
+	_ = p.cache.DelByTag(ctx, MenuFragmentSiteCacheTag(siteID))
+}
@@ -0,0 +1,233 @@
+package pages
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/gowool/cr"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ PageRevisions = (*DefaultPageRevisions)(nil)
+
+type PageFieldChange = model.FieldChange
+
+type PageRevisions interface {
+	// Save stores the page with the saver and records its revision in one unit of work,
+	// then prunes the revisions of the page exceeding the retention of the configuration.
+	// The author of the revision is the context actor.
+	Save(ctx context.Context, m *model.Page, message string, saver func(context.Context, *model.Page) error) error
+
+	// Diff returns the changed fields between the revisions.
+	Diff(ctx context.Context, fromID, toID int64) ([]PageFieldChange, error)
+
+	// Revert returns the current page with the fields of the revision, ready to be saved.
+	Revert(ctx context.Context, id int64) (model.Page, error)
+}
+
+type DefaultPageRevisions struct {
+	pageRepo     repository.Page
+	revisionRepo repository.PageRevision
+	cfgRepo      repository.Configuration
+	uow          repository.UnitOfWork
+}
+
+func NewDefaultPageRevisions(
+	pageRepo repository.Page,
+	revisionRepo repository.PageRevision,
+	cfgRepo repository.Configuration,
+	uow repository.UnitOfWork,
+) *DefaultPageRevisions {
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	if revisionRepo == nil {
+		panic("page revision repository is not specified")
+	}
+	if cfgRepo == nil {
+		panic("configuration repository is not specified")
+	}
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+	return &DefaultPageRevisions{
+		pageRepo:     pageRepo,
+		revisionRepo: revisionRepo,
+		cfgRepo:      cfgRepo,
+		uow:          uow,
+	}
+}
+
+func (r *DefaultPageRevisions) Save(ctx context.Context, m *model.Page, message string, saver func(context.Context, *model.Page) error) error {
+	return r.uow.Do(ctx, func(ctx context.Context) error {
+		if err := saver(ctx, m); err != nil {
+			return err
+		}
+
+		snapshot := *m
+		snapshot.Site, snapshot.Parent, snapshot.Children = nil, nil, nil
+
+		if err := r.revisionRepo.Create(ctx, &model.PageRevision{
+			PageID:  m.ID,
+			SiteID:  m.SiteID,
+			Page:    snapshot,
+			Author:  CtxActor(ctx),
+			Message: message,
+			Created: time.Now().UTC(),
+		}); err != nil {
+			return err
+		}
+
+		return r.prune(ctx, m.ID)
+	})
+}
+
+func (r *DefaultPageRevisions) Diff(ctx context.Context, fromID, toID int64) ([]PageFieldChange, error) {
+	from, err := r.revisionRepo.FindByID(ctx, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := r.revisionRepo.FindByID(ctx, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	if from.PageID != to.PageID {
+		return nil, &ValidationError{Errors: []FieldError{{
+			Field:   "to",
+			Value:   toID,
+			Message: fmt.Sprintf("revision belongs to page %d instead of %d", to.PageID, from.PageID),
+		}}}
+	}
+
+	return PageDiff(from.Page, to.Page)
+}
+
+func (r *DefaultPageRevisions) Revert(ctx context.Context, id int64) (model.Page, error) {
+	revision, err := r.revisionRepo.FindByID(ctx, id)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	current, err := r.pageRepo.FindByID(ctx, revision.PageID)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	m := revision.Page
+	m.ID = current.ID
+	m.SiteID = current.SiteID
+	m.Created = current.Created
+	m.Updated = current.Updated
+	// the publication belongs to the workflow, not to the content
+	m.Published = current.Published
+	m.State = current.State
+	return m, nil
+}
+
+// prune deletes the revisions exceeding the retention, keeping the latest one.
+func (r *DefaultPageRevisions) prune(ctx context.Context, pageID int64) error {
+	cfg, err := r.cfgRepo.Load(ctx)
+	if err != nil {
+		return err
+	}
+	retention := cfg.PageRevisions
+	if retention == nil || retention.IsZero() {
+		return nil
+	}
+
+	revisions, err := r.revisionRepo.Find(ctx, cr.New().
+		SetFilter(cr.Filter{
+			Conditions: []any{cr.Condition{Column: "page_id", Operator: cr.OpEqual, Value: pageID}},
+		}).
+		SetSortBy(cr.Sort{Column: "id", Order: "DESC"}))
+	if err != nil {
+		return err
+	}
+
+	var expired time.Time
+	if retention.MaxDays > 0 {
+		expired = time.Now().UTC().AddDate(0, 0, -retention.MaxDays)
+	}
+
+	var ids []int64
+	for i, revision := range revisions {
+		if i == 0 {
+			continue
+		}
+		if (retention.MaxCount > 0 && i >= retention.MaxCount) || (!expired.IsZero() && revision.Created.Before(expired)) {
+			ids = append(ids, revision.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.revisionRepo.Delete(ctx, ids...)
+}
+
+// PageDiff compares the json representations of the pages field by field.
+// The identity and timestamps of the records are skipped.
+func PageDiff(from, to model.Page) ([]PageFieldChange, error) {
+	return FieldDiff(from, to, "id", "created", "updated")
+}
+
+// FieldDiff compares the json representations of the values field by field, skipping the fields.
+// A nil value has no fields, so all the fields of the other one are changed.
+func FieldDiff(from, to any, skip ...string) ([]model.FieldChange, error) {
+	a, err := jsonFields(from)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := jsonFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(a)+len(b))
+	for field := range a {
+		fields = append(fields, field)
+	}
+	for field := range b {
+		if _, ok := a[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	changes := make([]model.FieldChange, 0)
+	for _, field := range fields {
+		if slices.Contains(skip, field) {
+			continue
+		}
+		if !reflect.DeepEqual(a[field], b[field]) {
+			changes = append(changes, model.FieldChange{Field: field, From: a[field], To: b[field]})
+		}
+	}
+	return changes, nil
+}
+
+func jsonFields(v any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if v == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,63 @@
+package pages
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func TestPageRevisionsPrune(t *testing.T) {
+	old := time.Now().UTC().AddDate(0, 0, -10)
+
+	tests := []struct {
+		name      string
+		retention *model.RevisionRetention
+		want      []int64
+	}{
+		{name: "not configured", want: []int64{1, 2, 3, 4, 5}},
+		{name: "unlimited", retention: &model.RevisionRetention{}, want: []int64{1, 2, 3, 4, 5}},
+		{name: "max count", retention: &model.RevisionRetention{MaxCount: 2}, want: []int64{4, 5}},
+		{name: "max days", retention: &model.RevisionRetention{MaxDays: 5}, want: []int64{3, 4, 5}},
+		{name: "latest kept", retention: &model.RevisionRetention{MaxCount: 1, MaxDays: 1}, want: []int64{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pageRepo := memory.NewPageRepository(sql.ErrNoRows)
+			revisionRepo := memory.NewRepository[model.PageRevision](sql.ErrNoRows)
+			cfg := model.NewConfiguration()
+			cfg.PageRevisions = tt.retention
+
+			revisions := NewDefaultPageRevisions(pageRepo, revisionRepo, memory.NewConfigurationRepository(cfg), &memory.UnitOfWork{})
+
+			m := model.Page{Name: "Page", SiteID: 1}
+			if err := pageRepo.Create(ctx, &m); err != nil {
+				t.Fatal(err)
+			}
+			for range 2 {
+				if err := revisionRepo.Create(ctx, &model.PageRevision{PageID: m.ID, Created: old}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for range 3 {
+				if err := revisions.Save(ctx, &m, "", pageRepo.Update); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var ids []int64
+			for _, revision := range revisionRepo.FindAll(func(model.PageRevision) bool { return true }) {
+				ids = append(ids, revision.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("revisions = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gowool/cr"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ PageTree = (*DefaultPageTree)(nil)
+
+type PageTree interface {
+	// Create computes the page URL from its parent and saves the page.
+	Create(ctx context.Context, m *model.Page) error
+
+	// Update saves the page and recomputes the URLs of all its descendants
+	// when the page URL has been changed.
+	Update(ctx context.Context, m *model.Page) error
+
+	// Tree returns the root pages of the site with nested children sorted by position.
+	Tree(ctx context.Context, siteID int64) ([]model.Page, error)
+
+	// Move moves the page under the new parent (nil means the site root) at the position
+	// and keeps the positions of the new siblings dense. The moved page, its descendants whose URL
+	// has been changed and the siblings whose position has been changed are saved by saver,
+	// so they are validated and recorded like any other edit. Nil saver saves them to the repository.
+	Move(ctx context.Context, id int64, parentID *int64, position int, saver func(context.Context, *model.Page) error) error
+
+	// Reorder sets the positions of the sibling pages in the order of ids,
+	// the pages whose position has been changed are saved by saver, nil saver saves them to the repository.
+	Reorder(ctx context.Context, siteID int64, parentID *int64, ids []int64, saver func(context.Context, *model.Page) error) error
+}
+
+type DefaultPageTree struct {
+	pageRepo repository.Page
+	uow      repository.UnitOfWork
+	cache    Cache
+}
+
+func NewDefaultPageTree(pageRepo repository.Page, uow repository.UnitOfWork, cache Cache) *DefaultPageTree {
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+	return &DefaultPageTree{
+		pageRepo: pageRepo,
+		uow:      uow,
+		cache:    cache,
+	}
+}
+
+func (t *DefaultPageTree) Create(ctx context.Context, m *model.Page) error {
+	if m == nil {
+		return errors.New("page tree: create called with nil model")
+	}
+
+	if err := t.withParent(ctx, m); err != nil {
+		return err
+	}
+
+	page := m.WithFixedURL()
+	page.Parent = nil
+	page.Children = nil
+	*m = page
+
+	// the cache repository purges the parent and the menus of the site
+	return t.pageRepo.Create(ctx, m)
+}
+
+func (t *DefaultPageTree) Update(ctx context.Context, m *model.Page) error {
+	if m == nil {
+		return errors.New("page tree: update called with nil model")
+	}
+
+	data, err := t.prepare(ctx, m)
+	if err != nil {
+		return err
+	}
+	return t.save(ctx, data, nil)
+}
+
+func (t *DefaultPageTree) Tree(ctx context.Context, siteID int64) ([]model.Page, error) {
+	data, err := t.pageRepo.Find(ctx, cr.New().SetFilter(cr.Filter{
+		Conditions: []any{cr.Condition{Column: "site_id", Operator: cr.OpEqual, Value: siteID}},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return BuildPageTree(data), nil
+}
+
+func (t *DefaultPageTree) Move(ctx context.Context, id int64, parentID *int64, position int, saver func(context.Context, *model.Page) error) error {
+	m, err := t.pageRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	oldParentID := m.ParentID
+	m.ParentID = parentID
+
+	data, err := t.prepare(ctx, &m)
+	if err != nil {
+		return err
+	}
+
+	siblings, err := t.siblings(ctx, m.SiteID, parentID)
+	if err != nil {
+		return err
+	}
+	siblings = slices.DeleteFunc(siblings, func(item model.Page) bool {
+		return item.ID == m.ID
+	})
+
+	position = max(0, min(position, len(siblings)))
+	siblings = slices.Insert(siblings, position, m)
+
+	for i := range siblings {
+		if siblings[i].ID == m.ID {
+			data[0].Position = i
+			continue
+		}
+		if siblings[i].Position != i {
+			siblings[i].Position = i
+			data = append(data, siblings[i])
+		}
+	}
+
+	if !equalParentID(oldParentID, parentID) {
+		old, err := t.siblings(ctx, m.SiteID, oldParentID)
+		if err != nil {
+			return err
+		}
+		old = slices.DeleteFunc(old, func(item model.Page) bool {
+			return item.ID == m.ID
+		})
+		for i := range old {
+			if old[i].Position != i {
+				old[i].Position = i
+				data = append(data, old[i])
+			}
+		}
+	}
+
+	return t.save(ctx, data, saver)
+}
+
+func (t *DefaultPageTree) Reorder(ctx context.Context, siteID int64, parentID *int64, ids []int64, saver func(context.Context, *model.Page) error) error {
+	siblings, err := t.siblings(ctx, siteID, parentID)
+	if err != nil {
+		return err
+	}
+
+	index := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	for _, id := range ids {
+		if !slices.ContainsFunc(siblings, func(item model.Page) bool { return item.ID == id }) {
+			return fmt.Errorf("page tree: page %d is not a child of the parent: %w", id, ErrPageNotFound)
+		}
+	}
+
+	// pages missing in ids keep their relative order after the reordered ones
+	slices.SortStableFunc(siblings, func(a, b model.Page) int {
+		i, aok := index[a.ID]
+		j, bok := index[b.ID]
+		switch {
+		case aok && bok:
+			return i - j
+		case aok:
+			return -1
+		case bok:
+			return 1
+		}
+		return a.Position - b.Position
+	})
+
+	var data []model.Page
+	for i := range siblings {
+		if siblings[i].Position != i {
+			siblings[i].Position = i
+			data = append(data, siblings[i])
+		}
+	}
+	return t.save(ctx, data, saver)
+}
+
+// prepare fixes the URL of the page and returns it followed by its descendants
+// whose URL has been changed.
+func (t *DefaultPageTree) prepare(ctx context.Context, m *model.Page) ([]model.Page, error) {
+	old, err := t.pageRepo.FindByID(ctx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = t.withParent(ctx, m); err != nil {
+		return nil, err
+	}
+
+	page := m.WithFixedURL()
+	page.Children = nil
+
+	var changed []model.Page
+	if old.URL != page.URL {
+		// the parent is kept for the recomputation, the page is a root without it
+		if changed, err = t.recompute(ctx, page); err != nil {
+			return nil, err
+		}
+	}
+
+	page.Parent = nil
+	*m = page
+	return append([]model.Page{*m}, changed...), nil
+}
+
+func (t *DefaultPageTree) siblings(ctx context.Context, siteID int64, parentID *int64) ([]model.Page, error) {
+	cond := cr.Condition{Column: "parent_id", Operator: cr.OpIS, Value: nil}
+	if parentID != nil {
+		cond = cr.Condition{Column: "parent_id", Operator: cr.OpEqual, Value: *parentID}
+	}
+
+	data, err := t.pageRepo.Find(ctx, cr.New().
+		SetFilter(cr.Filter{
+			Conditions: []any{
+				cr.Condition{Column: "site_id", Operator: cr.OpEqual, Value: siteID},
+				cond,
+			},
+		}).
+		SetSortBy(cr.Sort{Column: "position", Order: "ASC"}, cr.Sort{Column: "id", Order: "ASC"}))
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(data, func(a, b model.Page) int {
+		return a.Position - b.Position
+	})
+	return data, nil
+}
+
+func (t *DefaultPageTree) withParent(ctx context.Context, m *model.Page) error {
+	m.Parent = nil
+	if m.ParentID == nil {
+		return nil
+	}
+
+	parent, err := t.pageRepo.FindByID(ctx, *m.ParentID)
+	if err != nil {
+		return err
+	}
+
+	if parent.SiteID != m.SiteID {
+		return fmt.Errorf("page tree: parent %d belongs to another site: %w", parent.ID, ErrPageNotFound)
+	}
+
+	for ancestor := parent; ; {
+		if ancestor.ID == m.ID {
+			return fmt.Errorf("page tree: page %d cannot be moved under itself or its descendant: %w", m.ID, ErrPageTreeCycle)
+		}
+		if ancestor.ParentID == nil {
+			break
+		}
+		if ancestor, err = t.pageRepo.FindByID(ctx, *ancestor.ParentID); err != nil {
+			return err
+		}
+	}
+
+	m.Parent = &parent
+	return nil
+}
+
+// recompute loads the subtree of the page, recomputes the URLs of its descendants
+// and returns those of them whose URL has been changed.
+func (t *DefaultPageTree) recompute(ctx context.Context, root model.Page) ([]model.Page, error) {
+	urls := map[int64]string{}
+
+	if err := t.children(ctx, &root, urls, map[int64]struct{}{root.ID: {}}); err != nil {
+		return nil, err
+	}
+
+	root = root.WithFixedURL()
+
+	var changed []model.Page
+	walkPages(root.Children, func(p model.Page) {
+		if urls[p.ID] != p.URL {
+			p.Parent = nil
+			p.Children = nil
+			changed = append(changed, p)
+		}
+	})
+
+	if err := t.checkCollisions(ctx, root, changed); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+func (t *DefaultPageTree) children(ctx context.Context, page *model.Page, urls map[int64]string, visited map[int64]struct{}) error {
+	children, err := t.pageRepo.FindByParentID(ctx, page.ID, time.Time{})
+	if err != nil {
+		if IsOneOfNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	for i := range children {
+		if _, ok := visited[children[i].ID]; ok {
+			return fmt.Errorf("page tree: page %d is visited twice: %w", children[i].ID, ErrPageTreeCycle)
+		}
+		visited[children[i].ID] = struct{}{}
+		urls[children[i].ID] = children[i].URL
+
+		if err = t.children(ctx, &children[i], urls, visited); err != nil {
+			return err
+		}
+	}
+	page.Children = children
+	return nil
+}
+
+func (t *DefaultPageTree) checkCollisions(ctx context.Context, root model.Page, changed []model.Page) error {
+	subtree := map[int64]struct{}{root.ID: {}}
+	walkPages(root.Children, func(p model.Page) {
+		subtree[p.ID] = struct{}{}
+	})
+
+	urls := map[string]int64{}
+	for _, p := range append([]model.Page{root}, changed...) {
+		if p.URL == "" || p.IsHybrid() {
+			continue
+		}
+
+		if id, ok := urls[p.URL]; ok {
+			return &PageURLConflictError{Page: p, ConflictID: id}
+		}
+		urls[p.URL] = p.ID
+
+		existing, err := t.pageRepo.FindByURL(ctx, p.SiteID, p.URL, time.Time{})
+		if err != nil {
+			if IsOneOfNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		if _, ok := subtree[existing.ID]; !ok {
+			return &PageURLConflictError{Page: p, ConflictID: existing.ID}
+		}
+	}
+	return nil
+}
+
+// save saves the pages by saver in one unit of work, nil saver saves them to the repository.
+func (t *DefaultPageTree) save(ctx context.Context, data []model.Page, saver func(context.Context, *model.Page) error) error {
+	if saver == nil {
+		saver = t.pageRepo.Update
+	}
+
+	if err := t.uow.Do(ctx, func(ctx context.Context) error {
+		for i := range data {
+			if err := saver(ctx, &data[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if t.cache != nil {
+		sites := map[int64]struct{}{}
+		for _, p := range data {
+			_ = t.cache.DelByTag(ctx, CacheTag(CachePrefixPage, p.ID))
+			sites[p.SiteID] = struct{}{}
+		}
+		for id := range sites {
+			_ = t.cache.DelByTag(ctx, MenuFragmentSiteCacheTag(id))
+		}
+	}
+	return nil
+}
+
+// BuildPageTree nests the pages by their parents and returns the roots sorted by position.
+func BuildPageTree(data []model.Page) []model.Page {
+	children := make(map[int64][]model.Page)
+	ids := make(map[int64]struct{}, len(data))
+	for _, p := range data {
+		ids[p.ID] = struct{}{}
+	}
+
+	var roots []model.Page
+	for _, p := range data {
+		if p.ParentID == nil {
+			roots = append(roots, p)
+			continue
+		}
+		if _, ok := ids[*p.ParentID]; !ok {
+			roots = append(roots, p)
+			continue
+		}
+		children[*p.ParentID] = append(children[*p.ParentID], p)
+	}
+
+	var nest func([]model.Page, map[int64]struct{}) []model.Page
+	nest = func(pages []model.Page, visited map[int64]struct{}) []model.Page {
+		slices.SortStableFunc(pages, func(a, b model.Page) int {
+			return a.Position - b.Position
+		})
+		for i := range pages {
+			if _, ok := visited[pages[i].ID]; ok {
+				continue
+			}
+			visited[pages[i].ID] = struct{}{}
+			pages[i].Children = nest(children[pages[i].ID], visited)
+		}
+		return pages
+	}
+	return nest(roots, map[int64]struct{}{})
+}
+
+func equalParentID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func walkPages(pages []model.Page, fn func(model.Page)) {
+	for _, p := range pages {
+		fn(p)
+		walkPages(p.Children, fn)
+	}
+}
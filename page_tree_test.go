@@ -0,0 +1,213 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func newTestPageTree(t *testing.T) (*DefaultPageTree, memory.PageRepository) {
+	t.Helper()
+
+	repo := memory.NewPageRepository(ErrPageNotFound)
+	return NewDefaultPageTree(repo, &memory.UnitOfWork{}, memory.NewCache()), repo
+}
+
+func createTestPage(t *testing.T, tree PageTree, m model.Page) model.Page {
+	t.Helper()
+
+	if m.SiteID == 0 {
+		m.SiteID = 1
+	}
+	if m.Pattern == "" {
+		m.Pattern = model.PageCMS
+	}
+	if err := tree.Create(context.Background(), &m); err != nil {
+		t.Fatalf("create page %q: %v", m.Name, err)
+	}
+	return m
+}
+
+func TestPageTreeUpdateRecomputesDescendantURLs(t *testing.T) {
+	ctx := context.Background()
+	tree, repo := newTestPageTree(t)
+
+	root := createTestPage(t, tree, model.Page{Name: "Home"})
+	blog := createTestPage(t, tree, model.Page{Name: "Blog", ParentID: &root.ID})
+	post := createTestPage(t, tree, model.Page{Name: "First Post", ParentID: &blog.ID})
+
+	if post.URL != "/blog/first-post" {
+		t.Fatalf("post url = %q, want /blog/first-post", post.URL)
+	}
+
+	blog.Slug = "news"
+	if err := tree.Update(ctx, &blog); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	if blog.URL != "/news" {
+		t.Errorf("blog url = %q, want /news", blog.URL)
+	}
+
+	got, err := repo.FindByID(ctx, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.URL != "/news/first-post" {
+		t.Errorf("post url = %q, want /news/first-post", got.URL)
+	}
+}
+
+func TestPageTreeUpdateRejectsCycle(t *testing.T) {
+	tree, _ := newTestPageTree(t)
+
+	root := createTestPage(t, tree, model.Page{Name: "Home"})
+	blog := createTestPage(t, tree, model.Page{Name: "Blog", ParentID: &root.ID})
+	post := createTestPage(t, tree, model.Page{Name: "Post", ParentID: &blog.ID})
+
+	blog.ParentID = &post.ID
+	if err := tree.Update(context.Background(), &blog); !errors.Is(err, ErrPageTreeCycle) {
+		t.Fatalf("err = %v, want ErrPageTreeCycle", err)
+	}
+}
+
+func TestPageTreeUpdateRejectsDescendantURLCollision(t *testing.T) {
+	ctx := context.Background()
+	tree, repo := newTestPageTree(t)
+
+	root := createTestPage(t, tree, model.Page{Name: "Home"})
+	blog := createTestPage(t, tree, model.Page{Name: "Blog", ParentID: &root.ID})
+	post := createTestPage(t, tree, model.Page{Name: "About", ParentID: &blog.ID})
+	_ = createTestPage(t, tree, model.Page{Name: "Landing", CustomURL: "/news/about"})
+
+	blog.Slug = "news-archive"
+	if err := tree.Update(ctx, &blog); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	blog.Slug = "news"
+	err := tree.Update(ctx, &blog)
+
+	var conflict *PageURLConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want *PageURLConflictError", err)
+	}
+	if conflict.Page.ID != post.ID {
+		t.Errorf("conflicting page = %d, want %d", conflict.Page.ID, post.ID)
+	}
+
+	got, _ := repo.FindByID(ctx, blog.ID)
+	if got.URL != "/news-archive" {
+		t.Errorf("blog url = %q, want it unchanged", got.URL)
+	}
+}
+
+func TestPageTreeMove(t *testing.T) {
+	ctx := context.Background()
+	tree, repo := newTestPageTree(t)
+
+	root := createTestPage(t, tree, model.Page{Name: "Home"})
+	blog := createTestPage(t, tree, model.Page{Name: "Blog", ParentID: &root.ID, Position: 0})
+	news := createTestPage(t, tree, model.Page{Name: "News", ParentID: &root.ID, Position: 1})
+	post := createTestPage(t, tree, model.Page{Name: "Post", ParentID: &blog.ID})
+	event := createTestPage(t, tree, model.Page{Name: "Event", ParentID: &news.ID, Position: 0})
+
+	var saved []int64
+	saver := func(ctx context.Context, m *model.Page) error {
+		saved = append(saved, m.ID)
+		return repo.Update(ctx, m)
+	}
+
+	if err := tree.Move(ctx, blog.ID, &news.ID, 0, saver); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+
+	// the descendant with the new URL and the re-numbered siblings go through the saver too
+	want := []int64{blog.ID, post.ID, event.ID, news.ID}
+	if !slices.Equal(saved, want) {
+		t.Errorf("saved = %v, want %v", saved, want)
+	}
+
+	got, _ := repo.FindByID(ctx, blog.ID)
+	if got.URL != "/news/blog" || got.Position != 0 {
+		t.Errorf("blog = %q at %d, want /news/blog at 0", got.URL, got.Position)
+	}
+
+	got, _ = repo.FindByID(ctx, post.ID)
+	if got.URL != "/news/blog/post" {
+		t.Errorf("post url = %q, want /news/blog/post", got.URL)
+	}
+
+	children, _ := repo.FindByParentID(ctx, news.ID, time.Time{})
+	for _, child := range children {
+		if child.ID != blog.ID && child.Position != 1 {
+			t.Errorf("sibling %d position = %d, want 1", child.ID, child.Position)
+		}
+	}
+
+	got, _ = repo.FindByID(ctx, news.ID)
+	if got.Position != 0 {
+		t.Errorf("old sibling position = %d, want 0", got.Position)
+	}
+}
+
+func TestPageTreeMoveFailsWithSaver(t *testing.T) {
+	ctx := context.Background()
+	tree, repo := newTestPageTree(t)
+
+	root := createTestPage(t, tree, model.Page{Name: "Home"})
+	blog := createTestPage(t, tree, model.Page{Name: "Blog", ParentID: &root.ID})
+	news := createTestPage(t, tree, model.Page{Name: "News", ParentID: &root.ID})
+
+	rejected := errors.New("rejected")
+	err := tree.Move(ctx, blog.ID, &news.ID, 0, func(context.Context, *model.Page) error {
+		return rejected
+	})
+	if !errors.Is(err, rejected) {
+		t.Fatalf("err = %v, want the error of the saver", err)
+	}
+
+	got, _ := repo.FindByID(ctx, blog.ID)
+	if got.URL != "/blog" {
+		t.Errorf("blog url = %q, want it unchanged", got.URL)
+	}
+}
+
+func TestPageTreeReorder(t *testing.T) {
+	ctx := context.Background()
+	tree, repo := newTestPageTree(t)
+
+	root := createTestPage(t, tree, model.Page{Name: "Home"})
+	a := createTestPage(t, tree, model.Page{Name: "A", ParentID: &root.ID, Position: 0})
+	b := createTestPage(t, tree, model.Page{Name: "B", ParentID: &root.ID, Position: 1})
+	c := createTestPage(t, tree, model.Page{Name: "C", ParentID: &root.ID, Position: 2})
+
+	var saved []int64
+	saver := func(ctx context.Context, m *model.Page) error {
+		saved = append(saved, m.ID)
+		return repo.Update(ctx, m)
+	}
+
+	if err := tree.Reorder(ctx, 1, &root.ID, []int64{c.ID, a.ID}, saver); err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+
+	for id, want := range map[int64]int{c.ID: 0, a.ID: 1, b.ID: 2} {
+		got, _ := repo.FindByID(ctx, id)
+		if got.Position != want {
+			t.Errorf("page %d position = %d, want %d", id, got.Position, want)
+		}
+	}
+	if len(saved) != 3 {
+		t.Errorf("saved = %v, want all the reordered pages", saved)
+	}
+
+	if err := tree.Reorder(ctx, 1, &root.ID, []int64{root.ID}, saver); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("err = %v, want ErrPageNotFound for a page of another parent", err)
+	}
+}
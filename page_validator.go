@@ -0,0 +1,160 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ PageValidator = (*DefaultPageValidator)(nil)
+
+type PageValidator interface {
+	// Validate checks that the page does not collide with other pages of the same site
+	// or with the registered routes. It returns *ValidationError on collision.
+	Validate(ctx context.Context, m model.Page, routes []*echo.Route) error
+}
+
+type DefaultPageValidator struct {
+	pageRepo repository.Page
+	cfgRepo  repository.Configuration
+}
+
+func NewDefaultPageValidator(pageRepo repository.Page, cfgRepo repository.Configuration) *DefaultPageValidator {
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	if cfgRepo == nil {
+		panic("configuration repository is not specified")
+	}
+	return &DefaultPageValidator{
+		pageRepo: pageRepo,
+		cfgRepo:  cfgRepo,
+	}
+}
+
+func (v *DefaultPageValidator) Validate(ctx context.Context, m model.Page, routes []*echo.Route) error {
+	if m.ParentID != nil && m.Parent == nil {
+		parent, err := v.pageRepo.FindByID(ctx, *m.ParentID)
+		if err != nil {
+			return err
+		}
+		m.Parent = &parent
+	}
+	m = m.WithFixedURL()
+
+	var errs []FieldError
+
+	if m.IsCMS() && m.URL != "" {
+		field := "slug"
+		if m.CustomURL != "" || m.Parent == nil {
+			field = "customURL"
+		}
+
+		msg, err := uniquePage(m.ID, func() (model.Page, error) {
+			return v.pageRepo.FindByURL(ctx, m.SiteID, m.URL, time.Time{})
+		})
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			errs = append(errs, FieldError{Field: field, Value: m.URL, Message: msg})
+		}
+
+		cfg, err := v.cfgRepo.Load(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range routes {
+			if r.Method == echo.RouteNotFound || isCatchAll(r.Path) || cfg.IgnorePattern(r.Path) {
+				continue
+			}
+			if matchRoute(r.Path, m.URL) {
+				errs = append(errs, FieldError{
+					Field:   field,
+					Value:   m.URL,
+					Message: fmt.Sprintf("url collides with the route %s %s", r.Method, r.Path),
+				})
+				break
+			}
+		}
+	}
+
+	if m.Alias != "" {
+		msg, err := uniquePage(m.ID, func() (model.Page, error) {
+			return v.pageRepo.FindByAlias(ctx, m.SiteID, m.Alias, time.Time{})
+		})
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			errs = append(errs, FieldError{Field: "alias", Value: m.Alias, Message: msg})
+		}
+	}
+
+	if !m.IsCMS() {
+		msg, err := uniquePage(m.ID, func() (model.Page, error) {
+			return v.pageRepo.FindByPattern(ctx, m.SiteID, m.Pattern, time.Time{})
+		})
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			errs = append(errs, FieldError{Field: "pattern", Value: m.Pattern, Message: msg})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func uniquePage(id int64, find func() (model.Page, error)) (string, error) {
+	existing, err := find()
+	if err != nil {
+		if IsOneOfNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if existing.ID != id {
+		return fmt.Sprintf("already used by page %d (%s)", existing.ID, existing.String()), nil
+	}
+	return "", nil
+}
+
+func isCatchAll(pattern string) bool {
+	return pattern == "" || pattern == "*" || pattern == "/*"
+}
+
+// matchRoute reports whether the url path matches the echo route pattern.
+func matchRoute(pattern, path string) bool {
+	pp := strings.Split(strings.Trim(pattern, "/"), "/")
+	up := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range pp {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(up) {
+			return false
+		}
+		if strings.HasPrefix(segment, ":") {
+			if up[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != up[i] {
+			return false
+		}
+	}
+	return len(pp) == len(up)
+}
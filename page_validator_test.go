@@ -0,0 +1,65 @@
+package pages
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func TestPageValidatorValidate(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewPageRepository(ErrPageNotFound)
+	tree := NewDefaultPageTree(repo, &memory.UnitOfWork{}, nil)
+	validator := NewDefaultPageValidator(repo, memory.NewConfigurationRepository(model.Configuration{}))
+
+	root := createTestPage(t, tree, model.Page{Name: "Home"})
+	blog := createTestPage(t, tree, model.Page{Name: "Blog", ParentID: &root.ID, Alias: "_page_alias_blog"})
+	_ = createTestPage(t, tree, model.Page{Name: "Contact", Pattern: "contact"})
+
+	routes := []*echo.Route{
+		{Method: http.MethodGet, Path: "/api/:id"},
+		{Method: http.MethodGet, Path: "/*"},
+	}
+
+	tests := []struct {
+		name  string
+		page  model.Page
+		field string
+	}{
+		{name: "unique", page: model.Page{SiteID: 1, Name: "News", ParentID: &root.ID, Pattern: model.PageCMS}},
+		{name: "same page", page: blog},
+		{name: "url", page: model.Page{SiteID: 1, Name: "Blog", ParentID: &root.ID, Pattern: model.PageCMS}, field: "slug"},
+		{name: "custom url", page: model.Page{SiteID: 1, Name: "Blog", CustomURL: "/blog", Pattern: model.PageCMS}, field: "customURL"},
+		{name: "route", page: model.Page{SiteID: 1, Name: "API", Slug: "api/users", ParentID: &root.ID, Pattern: model.PageCMS}, field: "slug"},
+		{name: "alias", page: model.Page{SiteID: 1, Name: "News", ParentID: &root.ID, Pattern: model.PageCMS, Alias: "_page_alias_blog"}, field: "alias"},
+		{name: "pattern", page: model.Page{SiteID: 1, Name: "Contact", Pattern: "contact"}, field: "pattern"},
+		{name: "other site", page: model.Page{SiteID: 2, Name: "Contact", Pattern: "contact"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(ctx, tt.page, routes)
+
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("err = %v, want *ValidationError", err)
+			}
+			if len(verr.Errors) != 1 || verr.Errors[0].Field != tt.field {
+				t.Errorf("errors = %v, want one error of the field %s", verr.Errors, tt.field)
+			}
+		})
+	}
+}
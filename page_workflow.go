@@ -0,0 +1,310 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gowool/cr"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ PageWorkflow = (*DefaultPageWorkflow)(nil)
+
+type PageWorkflow interface {
+	// Transitions returns the transitions of the configuration the context actor may apply to the page.
+	Transitions(ctx context.Context, id int64) ([]model.WorkflowTransition, error)
+
+	// Apply applies the transition to the page and records it in the history.
+	// The publish transition is the only one setting the publication time of the page,
+	// it publishes the snapshot of the page as well when a publisher is specified.
+	// The archive transition expires the page and unpublishes its snapshot.
+	Apply(ctx context.Context, id int64, transition, comment string) (model.Page, error)
+
+	// Publish applies the publish transition to the page,
+	// its template is frozen with the snapshot when withTemplate is set.
+	// The page is published at the given time, now when it is nil.
+	Publish(ctx context.Context, id int64, withTemplate bool, at *time.Time, comment string) (model.Page, error)
+
+	// PublishSite applies the publish transition to all the approved pages of the site in one unit of work.
+	PublishSite(ctx context.Context, siteID int64, withTemplate bool, at *time.Time, comment string) ([]model.Page, error)
+
+	// Unpublish applies the archive transition to the page.
+	Unpublish(ctx context.Context, id int64, comment string) (model.Page, error)
+
+	// Save stores the page with the saver. The new pages start as drafts
+	// and the changed pages in review go back to draft, so they have to be reviewed again.
+	Save(ctx context.Context, m *model.Page, saver func(context.Context, *model.Page) error) error
+}
+
+type DefaultPageWorkflow struct {
+	pageRepo       repository.Page
+	transitionRepo repository.PageTransition
+	revisionRepo   repository.PageRevision
+	cfgRepo        repository.Configuration
+	uow            repository.UnitOfWork
+	publisher      PagePublisher
+	cache          Cache
+}
+
+func NewDefaultPageWorkflow(
+	pageRepo repository.Page,
+	transitionRepo repository.PageTransition,
+	revisionRepo repository.PageRevision,
+	cfgRepo repository.Configuration,
+	uow repository.UnitOfWork,
+	publisher PagePublisher,
+	cache Cache,
+) *DefaultPageWorkflow {
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	if transitionRepo == nil {
+		panic("page transition repository is not specified")
+	}
+	if revisionRepo == nil {
+		panic("page revision repository is not specified")
+	}
+	if cfgRepo == nil {
+		panic("configuration repository is not specified")
+	}
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+	return &DefaultPageWorkflow{
+		pageRepo:       pageRepo,
+		transitionRepo: transitionRepo,
+		revisionRepo:   revisionRepo,
+		cfgRepo:        cfgRepo,
+		uow:            uow,
+		publisher:      publisher,
+		cache:          cache,
+	}
+}
+
+func (w *DefaultPageWorkflow) Transitions(ctx context.Context, id int64) ([]model.WorkflowTransition, error) {
+	m, err := w.pageRepo.FindByID(WithEditor(ctx, true), id)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := w.cfgRepo.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := CtxRoles(ctx)
+	transitions := make([]model.WorkflowTransition, 0)
+	for _, t := range cfg.WorkflowTransitions() {
+		if t.Allows(m.State) && t.Granted(roles) {
+			transitions = append(transitions, t)
+		}
+	}
+	return transitions, nil
+}
+
+func (w *DefaultPageWorkflow) Apply(ctx context.Context, id int64, transition, comment string) (model.Page, error) {
+	return w.apply(ctx, id, transition, comment, false, nil)
+}
+
+func (w *DefaultPageWorkflow) Publish(ctx context.Context, id int64, withTemplate bool, at *time.Time, comment string) (model.Page, error) {
+	return w.apply(ctx, id, model.TransitionPublish, comment, withTemplate, at)
+}
+
+func (w *DefaultPageWorkflow) Unpublish(ctx context.Context, id int64, comment string) (model.Page, error) {
+	return w.apply(ctx, id, model.TransitionArchive, comment, false, nil)
+}
+
+func (w *DefaultPageWorkflow) PublishSite(ctx context.Context, siteID int64, withTemplate bool, at *time.Time, comment string) ([]model.Page, error) {
+	data, err := w.pageRepo.Find(WithEditor(ctx, true), cr.New().SetFilter(cr.Filter{
+		Operator: cr.OpAND,
+		Conditions: []any{
+			cr.Condition{Column: "site_id", Operator: cr.OpEqual, Value: siteID},
+			cr.Condition{Column: "state", Operator: cr.OpEqual, Value: model.WorkflowApproved},
+		},
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	published := make([]model.Page, 0, len(data))
+	if err = w.uow.Do(ctx, func(ctx context.Context) error {
+		for _, page := range data {
+			m, err := w.apply(ctx, page.ID, model.TransitionPublish, comment, withTemplate, at)
+			if err != nil {
+				return err
+			}
+			published = append(published, m)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return published, nil
+}
+
+func (w *DefaultPageWorkflow) apply(ctx context.Context, id int64, transition, comment string, withTemplate bool, at *time.Time) (model.Page, error) {
+	m, err := w.pageRepo.FindByID(WithEditor(ctx, true), id)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	cfg, err := w.cfgRepo.Load(ctx)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	index := slices.IndexFunc(cfg.WorkflowTransitions(), func(t model.WorkflowTransition) bool {
+		return t.Name == transition
+	})
+	if index < 0 {
+		return model.Page{}, fmt.Errorf("%w: unknown transition %q", ErrTransitionNotAllowed, transition)
+	}
+	t := cfg.WorkflowTransitions()[index]
+
+	from := m.State
+	if from.IsZero() {
+		from = model.WorkflowDraft
+	}
+
+	if !t.Allows(from) {
+		return model.Page{}, fmt.Errorf("%w: %q from state %q", ErrTransitionNotAllowed, transition, from)
+	}
+	if !t.Granted(CtxRoles(ctx)) {
+		return model.Page{}, fmt.Errorf("%w: %q requires one of the roles %v", ErrTransitionForbidden, transition, t.Roles)
+	}
+
+	actor := CtxActor(ctx)
+	if t.FourEyes && !m.IsInternal() {
+		if err = w.fourEyes(ctx, m, actor); err != nil {
+			return model.Page{}, err
+		}
+	}
+
+	now := time.Now().UTC()
+	m.State = t.To
+
+	switch t.To {
+	case model.WorkflowPublished:
+		published := now
+		if at != nil {
+			published = at.UTC()
+		}
+		m.Published = &published
+		if m.Expired != nil && !m.Expired.After(published) {
+			m.Expired = nil
+		}
+	case model.WorkflowArchived:
+		m.Expired = &now
+	}
+
+	if err = w.uow.Do(ctx, func(ctx context.Context) error {
+		if err := w.pageRepo.Update(ctx, &m); err != nil {
+			return err
+		}
+
+		if err := w.transitionRepo.Create(ctx, &model.PageTransition{
+			PageID:     m.ID,
+			SiteID:     m.SiteID,
+			Transition: t.Name,
+			From:       from,
+			To:         t.To,
+			Actor:      actor,
+			Comment:    comment,
+			Created:    now,
+		}); err != nil {
+			return err
+		}
+
+		if w.publisher == nil {
+			return nil
+		}
+
+		switch t.To {
+		case model.WorkflowPublished:
+			_, err := w.publisher.PublishPage(ctx, m.ID, withTemplate)
+			return err
+		case model.WorkflowArchived:
+			if err := w.publisher.Unpublish(ctx, m.ID); err != nil && !IsOneOfNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return model.Page{}, err
+	}
+
+	if w.cache != nil {
+		_ = w.cache.DelByTag(ctx, CacheTag(CachePrefixPage, m.ID))
+		_ = w.cache.DelByTag(ctx, MenuFragmentSiteCacheTag(m.SiteID))
+	}
+	return m, nil
+}
+
+func (w *DefaultPageWorkflow) Save(ctx context.Context, m *model.Page, saver func(context.Context, *model.Page) error) error {
+	from := m.State
+	if from.IsZero() || from.IsReviewed() {
+		m.State = model.WorkflowDraft
+	}
+
+	return w.uow.Do(ctx, func(ctx context.Context) error {
+		if err := saver(ctx, m); err != nil {
+			return err
+		}
+
+		if !from.IsReviewed() {
+			return nil
+		}
+
+		return w.transitionRepo.Create(ctx, &model.PageTransition{
+			PageID:     m.ID,
+			SiteID:     m.SiteID,
+			Transition: model.TransitionEdit,
+			From:       from,
+			To:         m.State,
+			Actor:      CtxActor(ctx),
+			Created:    time.Now().UTC(),
+		})
+	})
+}
+
+// fourEyes checks that the actor neither submitted the page for review nor edited it last.
+func (w *DefaultPageWorkflow) fourEyes(ctx context.Context, m model.Page, actor string) error {
+	if actor == "" {
+		return fmt.Errorf("%w: four-eyes approval requires a known actor", ErrTransitionForbidden)
+	}
+
+	transitions, err := w.transitionRepo.Find(ctx, cr.New().
+		SetFilter(cr.Filter{
+			Operator: cr.OpAND,
+			Conditions: []any{
+				cr.Condition{Column: "page_id", Operator: cr.OpEqual, Value: m.ID},
+				cr.Condition{Column: "to", Operator: cr.OpEqual, Value: model.WorkflowInReview},
+			},
+		}).
+		SetSortBy(cr.Sort{Column: "id", Order: "DESC"}).
+		SetSize(1))
+	if err != nil {
+		return err
+	}
+	if len(transitions) > 0 && transitions[0].Actor == actor {
+		return fmt.Errorf("%w: the page was submitted for review by %q", ErrTransitionForbidden, actor)
+	}
+
+	revisions, err := w.revisionRepo.Find(ctx, cr.New().
+		SetFilter(cr.Filter{
+			Conditions: []any{cr.Condition{Column: "page_id", Operator: cr.OpEqual, Value: m.ID}},
+		}).
+		SetSortBy(cr.Sort{Column: "id", Order: "DESC"}).
+		SetSize(1))
+	if err != nil {
+		return err
+	}
+	if len(revisions) > 0 && revisions[0].Author == actor {
+		return fmt.Errorf("%w: the page was last edited by %q", ErrTransitionForbidden, actor)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package pages
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+type testWorkflow struct {
+	*DefaultPageWorkflow
+	pageRepo       memory.PageRepository
+	transitionRepo *memory.Repository[model.PageTransition]
+	revisions      *DefaultPageRevisions
+	snapshotRepo   memory.PageSnapshotRepository
+}
+
+func newTestWorkflow(t *testing.T) testWorkflow {
+	t.Helper()
+
+	pageRepo := memory.NewPageRepository(ErrPageNotFound)
+	transitionRepo := memory.NewRepository[model.PageTransition](sql.ErrNoRows)
+	revisionRepo := memory.NewRepository[model.PageRevision](ErrPageRevisionNotFound)
+	snapshotRepo := memory.NewPageSnapshotRepository(ErrPageSnapshotNotFound)
+	cfgRepo := memory.NewConfigurationRepository(model.NewConfiguration())
+	uow := &memory.UnitOfWork{}
+
+	publisher := NewDefaultPagePublisher(pageRepo, memory.NewTemplateRepository(sql.ErrNoRows), snapshotRepo, uow, nil)
+
+	return testWorkflow{
+		DefaultPageWorkflow: NewDefaultPageWorkflow(pageRepo, transitionRepo, revisionRepo, cfgRepo, uow, publisher, memory.NewCache()),
+		pageRepo:            pageRepo,
+		transitionRepo:      transitionRepo,
+		revisions:           NewDefaultPageRevisions(pageRepo, revisionRepo, cfgRepo, uow),
+		snapshotRepo:        snapshotRepo,
+	}
+}
+
+// edit saves the page as the actor, the way the admin api does.
+func (w testWorkflow) edit(t *testing.T, actor string, m *model.Page, saver func(context.Context, *model.Page) error) {
+	t.Helper()
+
+	ctx := WithActor(context.Background(), actor)
+	if err := w.revisions.Save(ctx, m, "", func(ctx context.Context, m *model.Page) error {
+		return w.Save(ctx, m, saver)
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (w testWorkflow) apply(actor, role string, id int64, transition string) (model.Page, error) {
+	ctx := WithRoles(WithActor(context.Background(), actor), role)
+	return w.Apply(ctx, id, transition, "")
+}
+
+func TestPageWorkflowFourEyes(t *testing.T) {
+	tests := []struct {
+		name     string
+		editor   string
+		lastEdit string
+		reviewer string
+		want     error
+	}{
+		{name: "other reviewer", editor: "alice", reviewer: "bob"},
+		{name: "submitter approves", editor: "alice", reviewer: "alice", want: ErrTransitionForbidden},
+		{name: "last editor approves", editor: "alice", lastEdit: "bob", reviewer: "bob", want: ErrTransitionForbidden},
+		{name: "unknown actor", editor: "alice", want: ErrTransitionForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newTestWorkflow(t)
+
+			m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS}
+			w.edit(t, tt.editor, &m, w.pageRepo.Create)
+			if tt.lastEdit != "" {
+				w.edit(t, tt.lastEdit, &m, w.pageRepo.Update)
+			}
+
+			if _, err := w.apply(tt.editor, model.RoleEditor, m.ID, model.TransitionSubmit); err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := w.apply(tt.reviewer, model.RoleReviewer, m.ID, model.TransitionApprove)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && m.State != model.WorkflowApproved {
+				t.Errorf("state = %q, want %q", m.State, model.WorkflowApproved)
+			}
+		})
+	}
+}
+
+func TestPageWorkflowFourEyesSkipsInternalPages(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	m := model.Page{SiteID: 1, Name: "Not found", Pattern: model.PageInternalPrefix + "404"}
+	w.edit(t, "alice", &m, w.pageRepo.Create)
+
+	if _, err := w.apply("alice", model.RoleEditor, m.ID, model.TransitionSubmit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.apply("alice", model.RoleReviewer, m.ID, model.TransitionApprove); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestPageWorkflowEditSendsBackToDraft(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS}
+	w.edit(t, "alice", &m, w.pageRepo.Create)
+	if _, err := w.apply("alice", model.RoleEditor, m.ID, model.TransitionSubmit); err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ = w.pageRepo.FindByID(context.Background(), m.ID)
+	w.edit(t, "alice", &m, w.pageRepo.Update)
+
+	if m.State != model.WorkflowDraft {
+		t.Errorf("state = %q, want %q", m.State, model.WorkflowDraft)
+	}
+	last, _ := w.transitionRepo.FindByID(context.Background(), int64(w.transitionRepo.Len()))
+	if last.Transition != model.TransitionEdit || last.From != model.WorkflowInReview {
+		t.Errorf("last transition = %v", last)
+	}
+}
+
+func TestPageWorkflowPublish(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS}
+	w.edit(t, "alice", &m, w.pageRepo.Create)
+
+	publisher := WithRoles(WithActor(context.Background(), "carol"), model.RolePublisher)
+
+	// drafts are not published
+	if _, err := w.Publish(publisher, m.ID, false, nil, ""); !errors.Is(err, ErrTransitionNotAllowed) {
+		t.Fatalf("err = %v, want %v", err, ErrTransitionNotAllowed)
+	}
+	if w.snapshotRepo.Len() != 0 {
+		t.Fatalf("snapshots = %d, want 0", w.snapshotRepo.Len())
+	}
+
+	if _, err := w.apply("alice", model.RoleEditor, m.ID, model.TransitionSubmit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.apply("bob", model.RoleReviewer, m.ID, model.TransitionApprove); err != nil {
+		t.Fatal(err)
+	}
+
+	// the role of the transition is required
+	if _, err := w.Publish(WithActor(context.Background(), "alice"), m.ID, false, nil, ""); !errors.Is(err, ErrTransitionForbidden) {
+		t.Fatalf("err = %v, want %v", err, ErrTransitionForbidden)
+	}
+
+	m, err := w.Publish(publisher, m.ID, false, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.State != model.WorkflowPublished || m.Published == nil {
+		t.Errorf("state = %q, published = %v", m.State, m.Published)
+	}
+	if _, err = w.snapshotRepo.FindByID(context.Background(), m.ID); err != nil {
+		t.Errorf("snapshot err = %v", err)
+	}
+}
+
+func TestPageWorkflowPublishSite(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	approved := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS}
+	draft := model.Page{SiteID: 1, Name: "Draft", Pattern: model.PageCMS}
+	other := model.Page{SiteID: 2, Name: "Other", Pattern: model.PageCMS}
+	for _, m := range []*model.Page{&approved, &draft, &other} {
+		w.edit(t, "alice", m, w.pageRepo.Create)
+	}
+	for _, id := range []int64{approved.ID, other.ID} {
+		if _, err := w.apply("alice", model.RoleEditor, id, model.TransitionSubmit); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.apply("bob", model.RoleReviewer, id, model.TransitionApprove); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publisher := WithRoles(WithActor(context.Background(), "carol"), model.RolePublisher)
+	published, err := w.PublishSite(publisher, 1, false, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 || published[0].ID != approved.ID {
+		t.Fatalf("published = %v, want page %d only", published, approved.ID)
+	}
+	if w.snapshotRepo.Len() != 1 {
+		t.Errorf("snapshots = %d, want 1", w.snapshotRepo.Len())
+	}
+}
+
+func TestPageWorkflowPublishAt(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS}
+	w.edit(t, "alice", &m, w.pageRepo.Create)
+	if _, err := w.apply("alice", model.RoleEditor, m.ID, model.TransitionSubmit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.apply("bob", model.RoleReviewer, m.ID, model.TransitionApprove); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	m, err := w.Publish(WithRoles(WithActor(context.Background(), "carol"), model.RolePublisher), m.ID, false, &at, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Published == nil || !m.Published.Equal(at) {
+		t.Errorf("published = %v, want %v", m.Published, at)
+	}
+
+	snapshot, err := w.snapshotRepo.FindByID(context.Background(), m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Published.Equal(at) {
+		t.Errorf("snapshot published = %v, want %v", snapshot.Published, at)
+	}
+}
+
+func TestPageWorkflowUnpublish(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS}
+	w.edit(t, "alice", &m, w.pageRepo.Create)
+	if _, err := w.apply("alice", model.RoleEditor, m.ID, model.TransitionSubmit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.apply("bob", model.RoleReviewer, m.ID, model.TransitionApprove); err != nil {
+		t.Fatal(err)
+	}
+
+	publisher := WithRoles(WithActor(context.Background(), "carol"), model.RolePublisher)
+	if _, err := w.Publish(publisher, m.ID, false, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the role of the archive transition is required
+	if _, err := w.Unpublish(WithActor(context.Background(), "alice"), m.ID, ""); !errors.Is(err, ErrTransitionForbidden) {
+		t.Fatalf("err = %v, want %v", err, ErrTransitionForbidden)
+	}
+
+	m, err := w.Unpublish(publisher, m.ID, "outdated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.State != model.WorkflowArchived || m.Expired == nil {
+		t.Errorf("state = %q, expired = %v", m.State, m.Expired)
+	}
+	if _, err = w.snapshotRepo.FindByID(context.Background(), m.ID); !errors.Is(err, ErrPageSnapshotNotFound) {
+		t.Errorf("snapshot err = %v, want %v", err, ErrPageSnapshotNotFound)
+	}
+	last, _ := w.transitionRepo.FindByID(context.Background(), int64(w.transitionRepo.Len()))
+	if last.Transition != model.TransitionArchive || last.Actor != "carol" || last.Comment != "outdated" {
+		t.Errorf("last transition = %v", last)
+	}
+}
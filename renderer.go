@@ -48,6 +48,14 @@ func (renderer *Renderer) Render(w io.Writer, template string, data any, c echo.
 	url.User = nil
 	ctx = WithURL(ctx, url)
 
+	if CtxRoutePattern(ctx) == "" {
+		pattern := r.Pattern
+		if pattern == "" {
+			pattern = c.Path()
+		}
+		ctx = WithRoutePattern(ctx, pattern)
+	}
+
 	site := CtxSite(ctx)
 	if site == nil {
 		if site, ok = htmlData["site"].(*model.Site); !ok {
@@ -76,6 +84,12 @@ func (renderer *Renderer) Render(w io.Writer, template string, data any, c echo.
 		c.Response().Header().Set(key, value)
 	}
 
+	breadcrumbs := CtxBreadcrumbs(ctx)
+	if breadcrumbs == nil {
+		breadcrumbs = NewBreadcrumbs()
+		ctx = WithBreadcrumbs(ctx, breadcrumbs)
+	}
+
 	seo := CtxSEO(ctx).Site(site).Page(page)
 	ctx = WithSEO(ctx, seo)
 
@@ -87,6 +101,7 @@ func (renderer *Renderer) Render(w io.Writer, template string, data any, c echo.
 	htmlData["site"] = site
 	htmlData["page"] = page
 	htmlData["seo"] = seo
+	htmlData["breadcrumbs"] = breadcrumbs
 	htmlData["ctx"] = ctx
 	htmlData["csrf"] = ctx.Value("csrf")
 
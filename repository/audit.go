@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gowool/cr"
+
+	"github.com/gowool/pages/model"
+)
+
+// AuditLog stores the audit entries. The entries are never changed,
+// so the storage may be append only.
+type AuditLog interface {
+	Find(ctx context.Context, criteria *cr.Criteria) ([]model.AuditEntry, error)
+	FindAndCount(ctx context.Context, criteria *cr.Criteria) ([]model.AuditEntry, int, error)
+	FindByID(ctx context.Context, id int64) (model.AuditEntry, error)
+	Create(ctx context.Context, m *model.AuditEntry) error
+}
@@ -28,7 +28,7 @@ func (r repo[T, ID]) set(ctx context.Context, key string, m any, ids ...ID) {
 }
 
 func (r repo[T, ID]) tag(suffix string) string {
-	return fmt.Sprintf("%s:tag:%v", r.prefix, suffix)
+	return pages.CacheTag(r.prefix, suffix)
 }
 
 func (r repo[T, ID]) findByID(ctx context.Context, id ID) (m T, err error) {
@@ -19,7 +19,7 @@ func NewConfigurationRepository(inner repository.Configuration, c pages.Cache) C
 	return ConfigurationRepository{
 		Configuration: inner,
 		cache:         c,
-		key:           "cms::page:configuration",
+		key:           pages.CachePrefixConfiguration,
 	}
 }
 
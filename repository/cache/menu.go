@@ -18,7 +18,7 @@ type MenuRepository struct {
 func NewMenuRepository(inner repository.Menu, c pages.Cache) MenuRepository {
 	return MenuRepository{
 		Menu: inner,
-		repo: repo[model.Menu, int64]{inner: inner, cache: c, prefix: "cms::menu"},
+		repo: repo[model.Menu, int64]{inner: inner, cache: c, prefix: pages.CachePrefixMenu},
 	}
 }
 
@@ -30,24 +30,50 @@ func (r MenuRepository) Delete(ctx context.Context, ids ...int64) error {
 	return r.delete(ctx, ids...)
 }
 
+func (r MenuRepository) Create(ctx context.Context, m *model.Menu) error {
+	if m == nil {
+		return errors.New("cache: menu repository create called with nil model")
+	}
+
+	defer func() {
+		_ = r.cache.DelByTag(ctx, pages.MenuHandleCacheTag(m.Handle))
+	}()
+
+	return r.Menu.Create(ctx, m)
+}
+
+// Update purges the fragments of the previous handle too, they stay cached under it after a rename.
 func (r MenuRepository) Update(ctx context.Context, m *model.Menu) error {
 	if m == nil {
 		return errors.New("cache: menu repository update called with nil model")
 	}
 
-	defer r.del(ctx, m.ID)
+	handles := []string{m.Handle}
+	if old, err := r.Menu.FindByID(ctx, m.ID); err == nil && old.Handle != m.Handle {
+		handles = append(handles, old.Handle)
+	}
+
+	defer func() {
+		r.del(ctx, m.ID)
+		for _, handle := range handles {
+			_ = r.cache.DelByTag(ctx, pages.MenuHandleCacheTag(handle))
+		}
+	}()
 
 	return r.Menu.Update(ctx, m)
 }
 
-func (r MenuRepository) FindByHandle(ctx context.Context, handle string) (m model.Menu, err error) {
+func (r MenuRepository) FindByHandle(ctx context.Context, siteID *int64, handle string) (m model.Menu, err error) {
 	key := fmt.Sprintf("%s:handle:%s", r.prefix, handle)
+	if siteID != nil {
+		key = fmt.Sprintf("%s:site:%d:handle:%s", r.prefix, *siteID, handle)
+	}
 
 	if err = r.cache.Get(ctx, key, &m); err == nil {
 		return
 	}
 
-	if m, err = r.Menu.FindByHandle(ctx, handle); err != nil {
+	if m, err = r.Menu.FindByHandle(ctx, siteID, handle); err != nil {
 		return
 	}
 
@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func TestMenuRepositoryUpdatePurgesPreviousHandle(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewCache()
+	repo := NewMenuRepository(memory.NewMenuRepository(pages.ErrMenuNotFound), c)
+
+	m := model.Menu{Name: "Main", Handle: "main"}
+	if err := repo.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Handle = "primary"
+	if err := repo.Update(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range []string{pages.MenuHandleCacheTag("main"), pages.MenuHandleCacheTag("primary")} {
+		if !slices.Contains(c.Purged(), tag) {
+			t.Errorf("update purged %v, want %s", c.Purged(), tag)
+		}
+	}
+}
@@ -18,7 +18,7 @@ type NodeRepository struct {
 func NewNodeRepository(inner repository.Node, c pages.Cache) NodeRepository {
 	return NodeRepository{
 		Node: inner,
-		repo: repo[model.Node, int64]{inner: inner, cache: c, prefix: "cms::node"},
+		repo: repo[model.Node, int64]{inner: inner, cache: c, prefix: pages.CachePrefixNode},
 	}
 }
 
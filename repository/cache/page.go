@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gowool/echox"
+
 	"github.com/gowool/pages"
 	"github.com/gowool/pages/model"
 	"github.com/gowool/pages/repository"
 )
 
+// PageRepository caches the pages by the mode of the context as well,
+// so the drafts read by the editors and the admin api never reach the visitors
+// when a snapshot repository is decorated below it.
 type PageRepository struct {
 	repository.Page
 	repo[model.Page, int64]
@@ -19,18 +24,18 @@ type PageRepository struct {
 func NewPageRepository(inner repository.Page, c pages.Cache) PageRepository {
 	return PageRepository{
 		Page: inner,
-		repo: repo[model.Page, int64]{inner: inner, cache: c, prefix: "cms::page"},
+		repo: repo[model.Page, int64]{inner: inner, cache: c, prefix: pages.CachePrefixPage},
 	}
 }
 
 func (r PageRepository) FindByID(ctx context.Context, id int64) (m model.Page, err error) {
-	key := fmt.Sprintf("%s:id:%v", r.prefix, id)
+	key := fmt.Sprintf("%s:%s:id:%v", r.prefix, mode(ctx), id)
 
 	if err = r.cache.Get(ctx, key, &m); err == nil {
 		return
 	}
 
-	if m, err = r.findByID(ctx, id); err != nil {
+	if m, err = r.Page.FindByID(ctx, id); err != nil {
 		return
 	}
 
@@ -39,7 +44,7 @@ func (r PageRepository) FindByID(ctx context.Context, id int64) (m model.Page, e
 }
 
 func (r PageRepository) FindByParentID(ctx context.Context, parentID int64, now time.Time) (pages []model.Page, err error) {
-	key := fmt.Sprintf("%s:parent:%d", r.prefix, parentID)
+	key := fmt.Sprintf("%s:%s:parent:%d", r.prefix, mode(ctx), parentID)
 
 	if err = r.cache.Get(ctx, key, &pages); err == nil {
 		for _, p := range pages {
@@ -68,7 +73,7 @@ INNER:
 }
 
 func (r PageRepository) FindByPattern(ctx context.Context, siteID int64, pattern string, now time.Time) (m model.Page, err error) {
-	key := fmt.Sprintf("%s:pattern:%d:%s", r.prefix, siteID, pattern)
+	key := fmt.Sprintf("%s:%s:pattern:%d:%s", r.prefix, mode(ctx), siteID, pattern)
 
 	if r.get(ctx, key, now, &m) {
 		return
@@ -83,7 +88,7 @@ func (r PageRepository) FindByPattern(ctx context.Context, siteID int64, pattern
 }
 
 func (r PageRepository) FindByAlias(ctx context.Context, siteID int64, alias string, now time.Time) (m model.Page, err error) {
-	key := fmt.Sprintf("%s:alias:%d:%s", r.prefix, siteID, alias)
+	key := fmt.Sprintf("%s:%s:alias:%d:%s", r.prefix, mode(ctx), siteID, alias)
 
 	if r.get(ctx, key, now, &m) {
 		return
@@ -98,7 +103,7 @@ func (r PageRepository) FindByAlias(ctx context.Context, siteID int64, alias str
 }
 
 func (r PageRepository) FindByURL(ctx context.Context, siteID int64, url string, now time.Time) (m model.Page, err error) {
-	key := fmt.Sprintf("%s:url:%d:%s", r.prefix, siteID, url)
+	key := fmt.Sprintf("%s:%s:url:%d:%s", r.prefix, mode(ctx), siteID, url)
 
 	if r.get(ctx, key, now, &m) {
 		return
@@ -112,8 +117,39 @@ func (r PageRepository) FindByURL(ctx context.Context, siteID int64, url string,
 	return
 }
 
+func (r PageRepository) Create(ctx context.Context, m *model.Page) error {
+	if m == nil {
+		return errors.New("cache: page repository create called with nil model")
+	}
+
+	if err := r.Page.Create(ctx, m); err != nil {
+		return err
+	}
+
+	if m.ParentID != nil {
+		r.del(ctx, *m.ParentID)
+	}
+	_ = r.cache.DelByTag(ctx, pages.MenuFragmentSiteCacheTag(m.SiteID))
+	return nil
+}
+
+// Delete purges the menus rendered from the pages of the sites of the deleted pages too.
 func (r PageRepository) Delete(ctx context.Context, ids ...int64) error {
-	return r.delete(ctx, ids...)
+	sites := map[int64]struct{}{}
+	for _, id := range ids {
+		if m, err := r.Page.FindByID(pages.WithEditor(ctx, true), id); err == nil {
+			sites[m.SiteID] = struct{}{}
+		}
+	}
+
+	if err := r.delete(ctx, ids...); err != nil {
+		return err
+	}
+
+	for id := range sites {
+		_ = r.cache.DelByTag(ctx, pages.MenuFragmentSiteCacheTag(id))
+	}
+	return nil
 }
 
 func (r PageRepository) Update(ctx context.Context, m *model.Page) error {
@@ -128,11 +164,11 @@ func (r PageRepository) Update(ctx context.Context, m *model.Page) error {
 
 func (r PageRepository) set(ctx context.Context, key string, m model.Page) {
 	tags := []string{
-		fmt.Sprintf("%s:tag:%d", r.prefix, m.ID),
-		fmt.Sprintf("cms:site:tag:%d", m.SiteID),
+		pages.CacheTag(r.prefix, m.ID),
+		pages.CacheTag(pages.CachePrefixSite, m.SiteID),
 	}
 	if m.ParentID != nil {
-		tags = append(tags, fmt.Sprintf("%s:tag:%d", r.prefix, m.ParentID))
+		tags = append(tags, pages.CacheTag(r.prefix, *m.ParentID))
 	}
 
 	_ = r.cache.Set(ctx, key, m, tags...)
@@ -148,3 +184,14 @@ func (r PageRepository) get(ctx context.Context, key string, now time.Time, m *m
 	}
 	return false
 }
+
+// mode returns the part of the keys distinguishing the editors and the areas from the visitors.
+func mode(ctx context.Context) string {
+	if pages.CtxEditor(ctx) {
+		return "editor"
+	}
+	if area := echox.CtxArea(ctx); area != "" {
+		return area
+	}
+	return "public"
+}
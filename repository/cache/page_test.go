@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository/snapshot"
+)
+
+func TestPageRepositoryPurgesSiteMenuFragments(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewCache()
+	repo := NewPageRepository(memory.NewPageRepository(pages.ErrPageNotFound), c)
+
+	tag := pages.MenuFragmentSiteCacheTag(3)
+	if err := c.Set(ctx, "fragment", "<ul></ul>", tag); err != nil {
+		t.Fatal(err)
+	}
+
+	m := model.Page{SiteID: 3, Name: "Blog", Pattern: model.PageCMS}
+	if err := repo.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(c.Purged(), tag) {
+		t.Errorf("create purged %v, want %s", c.Purged(), tag)
+	}
+
+	if err := c.Set(ctx, "fragment", "<ul></ul>", tag); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, m.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var fragment string
+	if err := c.Get(ctx, "fragment", &fragment); err == nil {
+		t.Errorf("delete kept the menu fragment of the site")
+	}
+}
+
+func TestPageRepositoryKeepsDraftsFromVisitors(t *testing.T) {
+	ctx := context.Background()
+	editorCtx := pages.WithEditor(ctx, true)
+
+	inner := memory.NewPageRepository(pages.ErrPageNotFound)
+	snapshots := memory.NewPageSnapshotRepository(pages.ErrPageSnapshotNotFound)
+	repo := NewPageRepository(snapshot.NewPageRepository(inner, snapshots), memory.NewCache())
+
+	published := time.Now().UTC().Add(-time.Hour)
+	m := model.Page{SiteID: 1, Name: "Home", Title: "Published", Pattern: model.PageCMS, URL: "/", Published: &published}
+	if err := inner.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	s := model.NewPageSnapshot(m, nil, "", published)
+	if err := snapshots.Create(ctx, &s); err != nil {
+		t.Fatal(err)
+	}
+	m.Title = "Draft"
+	if err := inner.Update(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, find := range []func(context.Context) (model.Page, error){
+		func(ctx context.Context) (model.Page, error) { return repo.FindByID(ctx, m.ID) },
+		func(ctx context.Context) (model.Page, error) { return repo.FindByURL(ctx, 1, "/", time.Now().UTC()) },
+	} {
+		draft, err := find(editorCtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if draft.Title != "Draft" {
+			t.Errorf("editor title = %q, want %q", draft.Title, "Draft")
+		}
+
+		served, err := find(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if served.Title != "Published" {
+			t.Errorf("visitor title = %q, want %q", served.Title, "Published")
+		}
+	}
+}
@@ -22,7 +22,7 @@ type SiteRepository struct {
 func NewSiteRepository(inner repository.Site, c pages.Cache) SiteRepository {
 	return SiteRepository{
 		Site: inner,
-		repo: repo[model.Site, int64]{inner: inner, cache: c, prefix: "cms::site"},
+		repo: repo[model.Site, int64]{inner: inner, cache: c, prefix: pages.CachePrefixSite},
 	}
 }
 
@@ -44,9 +44,11 @@ INNER:
 		return
 	}
 
-	r.set(ctx, key, internal.Map(sites, func(item model.Site) int64 {
-		return item.ID
-	}))
+	// the sites going live are not in the list yet, so it is purged by the hosts tag as well
+	tags := internal.Map(sites, func(item model.Site) string {
+		return r.tag(fmt.Sprintf("%d", item.ID))
+	})
+	_ = r.cache.Set(ctx, key, sites, append(tags, pages.SiteHostsCacheTag())...)
 	return
 }
 
@@ -67,3 +69,11 @@ func (r SiteRepository) Update(ctx context.Context, m *model.Site) error {
 
 	return r.Site.Update(ctx, m)
 }
+
+func (r SiteRepository) Create(ctx context.Context, m *model.Site) error {
+	defer func() {
+		_ = r.cache.DelByTag(ctx, pages.SiteHostsCacheTag())
+	}()
+
+	return r.Site.Create(ctx, m)
+}
@@ -18,7 +18,7 @@ type TemplateRepository struct {
 func NewTemplateRepository(inner repository.Template, c pages.Cache) TemplateRepository {
 	return TemplateRepository{
 		Template: inner,
-		repo:     repo[model.Template, int64]{inner: inner, cache: c, prefix: "cms::template"},
+		repo:     repo[model.Template, int64]{inner: inner, cache: c, prefix: pages.CachePrefixTemplate},
 	}
 }
 
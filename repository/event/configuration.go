@@ -0,0 +1,50 @@
+package event
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type ConfigurationRepository struct {
+	repository.Configuration
+	dispatcher pages.EventDispatcher
+	auditor    pages.Auditor
+	uow        repository.UnitOfWork
+	logger     *zap.Logger
+}
+
+func NewConfigurationRepository(inner repository.Configuration, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) ConfigurationRepository {
+	return ConfigurationRepository{
+		Configuration: inner,
+		dispatcher:    dispatcher,
+		auditor:       auditor,
+		uow:           requireUnitOfWork(uow),
+		logger:        nopLogger(logger),
+	}
+}
+
+func (r ConfigurationRepository) Save(ctx context.Context, m *model.Configuration) error {
+	if m == nil {
+		return errors.New("event: configuration repository save called with nil model")
+	}
+
+	return r.uow.Do(ctx, func(ctx context.Context) error {
+		var before *model.Configuration
+		if old, err := r.Configuration.Load(ctx); err == nil {
+			before = &old
+		}
+
+		if err := r.Configuration.Save(ctx, m); err != nil {
+			return err
+		}
+
+		after := *m
+		return pages.Emit(ctx, r.auditor, r.dispatcher, r.logger, pages.NewEntityEvent(ctx, pages.EventEntityConfiguration, pages.EventActionUpdated, 0, nil, before, &after))
+	})
+}
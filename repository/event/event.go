@@ -0,0 +1,127 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/repository"
+)
+
+type entity interface {
+	GetID() int64
+}
+
+// repo saves the change and its audit entry in one unit of work and dispatches the event once it is committed.
+type repo[M entity] struct {
+	inner      repository.Repository[M, int64]
+	dispatcher pages.EventDispatcher
+	auditor    pages.Auditor
+	uow        repository.UnitOfWork
+	logger     *zap.Logger
+	entity     string
+	siteID     func(M) *int64
+	// redact hides the fields which must not leave the repository, like the secrets.
+	redact func(M) M
+}
+
+func (r repo[M]) create(ctx context.Context, m *M, create func(context.Context, *M) error) error {
+	if m == nil {
+		return fmt.Errorf("event: %s repository create called with nil model", r.entity)
+	}
+
+	return r.uow.Do(ctx, func(ctx context.Context) error {
+		if err := create(ctx, m); err != nil {
+			return err
+		}
+
+		after := *m
+		return r.emit(ctx, pages.EventActionCreated, after.GetID(), nil, &after)
+	})
+}
+
+func (r repo[M]) update(ctx context.Context, m *M, update func(context.Context, *M) error) error {
+	if m == nil {
+		return fmt.Errorf("event: %s repository update called with nil model", r.entity)
+	}
+
+	return r.uow.Do(ctx, func(ctx context.Context) error {
+		var before *M
+		if old, err := r.inner.FindByID(pages.WithEditor(ctx, true), (*m).GetID()); err == nil {
+			before = &old
+		}
+
+		if err := update(ctx, m); err != nil {
+			return err
+		}
+
+		after := *m
+		return r.emit(ctx, pages.EventActionUpdated, after.GetID(), before, &after)
+	})
+}
+
+func (r repo[M]) delete(ctx context.Context, ids []int64, del func(context.Context, ...int64) error) error {
+	return r.uow.Do(ctx, func(ctx context.Context) error {
+		befores := make([]*M, len(ids))
+		for i, id := range ids {
+			if old, err := r.inner.FindByID(pages.WithEditor(ctx, true), id); err == nil {
+				befores[i] = &old
+			}
+		}
+
+		if err := del(ctx, ids...); err != nil {
+			return err
+		}
+
+		// the models missing or failed to read are deleted as well, their events carry the ids only
+		for i, id := range ids {
+			if err := r.emit(ctx, pages.EventActionDeleted, id, befores[i], nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r repo[M]) emit(ctx context.Context, action string, id int64, before, after *M) error {
+	m := after
+	if m == nil {
+		m = before
+	}
+
+	var siteID *int64
+	if m != nil && r.siteID != nil {
+		siteID = r.siteID(*m)
+	}
+
+	if r.redact != nil {
+		before, after = redact(before, r.redact), redact(after, r.redact)
+	}
+
+	return pages.Emit(ctx, r.auditor, r.dispatcher, r.logger, pages.NewEntityEvent(ctx, r.entity, action, id, siteID, before, after))
+}
+
+func redact[M any](m *M, fn func(M) M) *M {
+	if m == nil {
+		return nil
+	}
+	v := fn(*m)
+	return &v
+}
+
+// requireUnitOfWork panics without a unit of work, the changes and their audit entries are saved in one.
+func requireUnitOfWork(uow repository.UnitOfWork) repository.UnitOfWork {
+	if uow == nil {
+		panic("event: unit of work is not specified")
+	}
+	return uow
+}
+
+func nopLogger(logger *zap.Logger) *zap.Logger {
+	if logger == nil {
+		return zap.NewNop()
+	}
+	return logger
+}
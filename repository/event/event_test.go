@@ -0,0 +1,180 @@
+package event
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type testDispatcher struct {
+	events []pages.Event
+	err    error
+}
+
+func (d *testDispatcher) Dispatch(_ context.Context, events ...pages.Event) error {
+	d.events = append(d.events, events...)
+	return d.err
+}
+
+type testAuditor struct {
+	events []pages.Event
+	inTx   []bool
+	err    error
+}
+
+func (a *testAuditor) Audit(ctx context.Context, event pages.Event) error {
+	a.events = append(a.events, event)
+	a.inTx = append(a.inTx, memory.InTx(ctx))
+	return a.err
+}
+
+func TestRepositoryDispatchesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := &testDispatcher{}
+	uow := repository.NewHookedUnitOfWork(&memory.UnitOfWork{})
+	repo := NewPageRepository(memory.NewPageRepository(pages.ErrPageNotFound), dispatcher, nil, uow, nil)
+
+	if err := uow.Do(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, &model.Page{SiteID: 1, Name: "About"}); err != nil {
+			return err
+		}
+		if len(dispatcher.events) > 0 {
+			t.Errorf("event dispatched before commit")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(dispatcher.events) != 1 || dispatcher.events[0].Meta().Action != pages.EventActionCreated {
+		t.Fatalf("events = %v, want the created one", dispatcher.events)
+	}
+
+	dispatcher.events = nil
+	errRollback := errors.New("rollback")
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		m, _ := repo.FindByID(ctx, 1)
+		m.Name = "Changed"
+		if err := repo.Update(ctx, &m); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("err = %v, want %v", err, errRollback)
+	}
+	if len(dispatcher.events) > 0 {
+		t.Errorf("events = %v dispatched for the rolled back change", dispatcher.events)
+	}
+}
+
+func TestRepositoryLogsDispatchErrors(t *testing.T) {
+	dispatcher := &testDispatcher{err: errors.New("stopped")}
+	repo := NewPageRepository(memory.NewPageRepository(pages.ErrPageNotFound), dispatcher, nil, repository.NewHookedUnitOfWork(&memory.UnitOfWork{}), nil)
+
+	if err := repo.Create(context.Background(), &model.Page{SiteID: 1, Name: "About"}); err != nil {
+		t.Errorf("err = %v, the change is saved already", err)
+	}
+}
+
+func TestRepositoryAuditsInUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := &testDispatcher{}
+	auditor := &testAuditor{}
+	inner := &memory.UnitOfWork{}
+	repo := NewPageRepository(memory.NewPageRepository(pages.ErrPageNotFound), dispatcher, auditor, repository.NewHookedUnitOfWork(inner), nil)
+
+	if err := repo.Create(ctx, &model.Page{SiteID: 1, Name: "About"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(auditor.events) != 1 || !auditor.inTx[0] {
+		t.Fatalf("audited = %v in unit of work = %v, want the created event in the unit of work", auditor.events, auditor.inTx)
+	}
+	if len(dispatcher.events) != 1 {
+		t.Fatalf("events = %v, want the created one", dispatcher.events)
+	}
+
+	auditor.err = errors.New("audit log is down")
+	dispatcher.events = nil
+
+	m, _ := repo.FindByID(ctx, 1)
+	m.Name = "Changed"
+	if err := repo.Update(ctx, &m); !errors.Is(err, auditor.err) {
+		t.Fatalf("err = %v, want %v", err, auditor.err)
+	}
+	if inner.RolledBack() != 1 {
+		t.Errorf("rolled back = %d, want the change rolled back with its audit entry", inner.RolledBack())
+	}
+	if len(dispatcher.events) > 0 {
+		t.Errorf("events = %v dispatched for the change failed to audit", dispatcher.events)
+	}
+}
+
+func TestRepositoryDeleteEmitsMissingIDs(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := &testDispatcher{}
+	auditor := &testAuditor{}
+	repo := NewPageRepository(memory.NewPageRepository(pages.ErrPageNotFound), dispatcher, auditor, repository.NewHookedUnitOfWork(&memory.UnitOfWork{}), nil)
+
+	if err := repo.Create(ctx, &model.Page{SiteID: 1, Name: "About"}); err != nil {
+		t.Fatal(err)
+	}
+	dispatcher.events, auditor.events = nil, nil
+
+	if err := repo.Delete(ctx, 1, 7); err != nil {
+		t.Fatal(err)
+	}
+	if len(dispatcher.events) != 2 || len(auditor.events) != 2 {
+		t.Fatalf("events = %v audited = %v, want two deleted events", dispatcher.events, auditor.events)
+	}
+
+	missing := dispatcher.events[1].(pages.PageEvent)
+	if missing.Action != pages.EventActionDeleted || missing.ID != 7 || missing.Before != nil || missing.After != nil {
+		t.Errorf("event = %+v, want the deleted event carrying the id only", missing)
+	}
+	if found := dispatcher.events[0].(pages.PageEvent); found.Before == nil || found.SiteID == nil || *found.SiteID != 1 {
+		t.Errorf("event = %+v, want the deleted event carrying the page", found)
+	}
+}
+
+func TestWebhookRepositoryRedactsSecret(t *testing.T) {
+	ctx := context.Background()
+	dispatcher := &testDispatcher{}
+	repo := NewWebhookRepository(memory.NewRepository[model.Webhook](sql.ErrNoRows), dispatcher, nil, repository.NewHookedUnitOfWork(&memory.UnitOfWork{}), nil)
+
+	m := model.Webhook{Name: "CI", URL: "https://ci.example.com", Secret: "s3cret"}
+	if err := repo.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Secret != "s3cret" {
+		t.Errorf("secret = %q, want the saved model untouched", m.Secret)
+	}
+
+	m.Secret = "rotated"
+	if err := repo.Update(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	e := dispatcher.events[1].(pages.WebhookEvent)
+	if e.Before.Secret == "s3cret" || e.After.Secret == "rotated" {
+		t.Fatalf("secrets %q and %q leaked", e.Before.Secret, e.After.Secret)
+	}
+	if e.Before.Secret == e.After.Secret {
+		t.Errorf("fingerprints %q are equal, want the rotation visible", e.Before.Secret)
+	}
+}
+
+func TestRepositoryRequiresUnitOfWork(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("the repository was created without a unit of work")
+		}
+	}()
+
+	NewPageRepository(memory.NewPageRepository(pages.ErrPageNotFound), nil, nil, nil, nil)
+}
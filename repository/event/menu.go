@@ -0,0 +1,43 @@
+package event
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type MenuRepository struct {
+	repository.Menu
+	repo[model.Menu]
+}
+
+func NewMenuRepository(inner repository.Menu, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) MenuRepository {
+	return MenuRepository{
+		Menu: inner,
+		repo: repo[model.Menu]{
+			inner:      inner,
+			dispatcher: dispatcher,
+			auditor:    auditor,
+			uow:        requireUnitOfWork(uow),
+			logger:     nopLogger(logger),
+			entity:     pages.EventEntityMenu,
+			siteID:     func(m model.Menu) *int64 { return m.SiteID },
+		},
+	}
+}
+
+func (r MenuRepository) Create(ctx context.Context, m *model.Menu) error {
+	return r.create(ctx, m, r.Menu.Create)
+}
+
+func (r MenuRepository) Update(ctx context.Context, m *model.Menu) error {
+	return r.update(ctx, m, r.Menu.Update)
+}
+
+func (r MenuRepository) Delete(ctx context.Context, ids ...int64) error {
+	return r.delete(ctx, ids, r.Menu.Delete)
+}
@@ -0,0 +1,42 @@
+package event
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type NodeRepository struct {
+	repository.Node
+	repo[model.Node]
+}
+
+func NewNodeRepository(inner repository.Node, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) NodeRepository {
+	return NodeRepository{
+		Node: inner,
+		repo: repo[model.Node]{
+			inner:      inner,
+			dispatcher: dispatcher,
+			auditor:    auditor,
+			uow:        requireUnitOfWork(uow),
+			logger:     nopLogger(logger),
+			entity:     pages.EventEntityNode,
+		},
+	}
+}
+
+func (r NodeRepository) Create(ctx context.Context, m *model.Node) error {
+	return r.create(ctx, m, r.Node.Create)
+}
+
+func (r NodeRepository) Update(ctx context.Context, m *model.Node) error {
+	return r.update(ctx, m, r.Node.Update)
+}
+
+func (r NodeRepository) Delete(ctx context.Context, ids ...int64) error {
+	return r.delete(ctx, ids, r.Node.Delete)
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type PageRepository struct {
+	repository.Page
+	repo[model.Page]
+}
+
+func NewPageRepository(inner repository.Page, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) PageRepository {
+	return PageRepository{
+		Page: inner,
+		repo: repo[model.Page]{
+			inner:      inner,
+			dispatcher: dispatcher,
+			auditor:    auditor,
+			uow:        requireUnitOfWork(uow),
+			logger:     nopLogger(logger),
+			entity:     pages.EventEntityPage,
+			siteID:     func(m model.Page) *int64 { return &m.SiteID },
+		},
+	}
+}
+
+func (r PageRepository) Create(ctx context.Context, m *model.Page) error {
+	return r.create(ctx, m, r.Page.Create)
+}
+
+func (r PageRepository) Update(ctx context.Context, m *model.Page) error {
+	return r.update(ctx, m, r.Page.Update)
+}
+
+func (r PageRepository) Delete(ctx context.Context, ids ...int64) error {
+	return r.delete(ctx, ids, r.Page.Delete)
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type SiteRepository struct {
+	repository.Site
+	repo[model.Site]
+}
+
+func NewSiteRepository(inner repository.Site, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) SiteRepository {
+	return SiteRepository{
+		Site: inner,
+		repo: repo[model.Site]{
+			inner:      inner,
+			dispatcher: dispatcher,
+			auditor:    auditor,
+			uow:        requireUnitOfWork(uow),
+			logger:     nopLogger(logger),
+			entity:     pages.EventEntitySite,
+			siteID:     func(m model.Site) *int64 { return &m.ID },
+		},
+	}
+}
+
+func (r SiteRepository) Create(ctx context.Context, m *model.Site) error {
+	return r.create(ctx, m, r.Site.Create)
+}
+
+func (r SiteRepository) Update(ctx context.Context, m *model.Site) error {
+	return r.update(ctx, m, r.Site.Update)
+}
+
+func (r SiteRepository) Delete(ctx context.Context, ids ...int64) error {
+	return r.delete(ctx, ids, r.Site.Delete)
+}
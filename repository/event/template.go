@@ -0,0 +1,42 @@
+package event
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type TemplateRepository struct {
+	repository.Template
+	repo[model.Template]
+}
+
+func NewTemplateRepository(inner repository.Template, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) TemplateRepository {
+	return TemplateRepository{
+		Template: inner,
+		repo: repo[model.Template]{
+			inner:      inner,
+			dispatcher: dispatcher,
+			auditor:    auditor,
+			uow:        requireUnitOfWork(uow),
+			logger:     nopLogger(logger),
+			entity:     pages.EventEntityTemplate,
+		},
+	}
+}
+
+func (r TemplateRepository) Create(ctx context.Context, m *model.Template) error {
+	return r.create(ctx, m, r.Template.Create)
+}
+
+func (r TemplateRepository) Update(ctx context.Context, m *model.Template) error {
+	return r.update(ctx, m, r.Template.Update)
+}
+
+func (r TemplateRepository) Delete(ctx context.Context, ids ...int64) error {
+	return r.delete(ctx, ids, r.Template.Delete)
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type WebhookRepository struct {
+	repository.Webhook
+	repo[model.Webhook]
+}
+
+func NewWebhookRepository(inner repository.Webhook, dispatcher pages.EventDispatcher, auditor pages.Auditor, uow repository.UnitOfWork, logger *zap.Logger) WebhookRepository {
+	return WebhookRepository{
+		Webhook: inner,
+		repo: repo[model.Webhook]{
+			inner:      inner,
+			dispatcher: dispatcher,
+			auditor:    auditor,
+			uow:        requireUnitOfWork(uow),
+			logger:     nopLogger(logger),
+			entity:     pages.EventEntityWebhook,
+			redact:     redactWebhook,
+		},
+	}
+}
+
+func (r WebhookRepository) Create(ctx context.Context, m *model.Webhook) error {
+	return r.create(ctx, m, r.Webhook.Create)
+}
+
+func (r WebhookRepository) Update(ctx context.Context, m *model.Webhook) error {
+	return r.update(ctx, m, r.Webhook.Update)
+}
+
+func (r WebhookRepository) Delete(ctx context.Context, ids ...int64) error {
+	return r.delete(ctx, ids, r.Webhook.Delete)
+}
+
+// redactWebhook replaces the secret by its fingerprint, so the audit log and the subscribers
+// see the secret changed without seeing the secret.
+func redactWebhook(m model.Webhook) model.Webhook {
+	if m.Secret != "" {
+		sum := sha256.Sum256([]byte(m.Secret))
+		m.Secret = "sha256:" + hex.EncodeToString(sum[:8])
+	}
+	return m
+}
@@ -8,5 +8,6 @@ import (
 
 type Menu interface {
 	Repository[model.Menu, int64]
-	FindByHandle(ctx context.Context, handle string) (model.Menu, error)
+	// FindByHandle returns the menu of the site with the handle, nil siteID means a global menu.
+	FindByHandle(ctx context.Context, siteID *int64, handle string) (model.Menu, error)
 }
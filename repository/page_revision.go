@@ -0,0 +1,7 @@
+package repository
+
+import "github.com/gowool/pages/model"
+
+type PageRevision interface {
+	Repository[model.PageRevision, int64]
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gowool/pages/model"
+)
+
+// PageSnapshot stores the published snapshots of the pages.
+// The finders have the semantics of the ones of Page applied to the snapshot columns.
+type PageSnapshot interface {
+	Repository[model.PageSnapshot, int64]
+	FindByParentID(ctx context.Context, parentID int64, now time.Time) ([]model.PageSnapshot, error)
+	FindByPattern(ctx context.Context, siteID int64, pattern string, now time.Time) (model.PageSnapshot, error)
+	FindByAlias(ctx context.Context, siteID int64, alias string, now time.Time) (model.PageSnapshot, error)
+	FindByURL(ctx context.Context, siteID int64, url string, now time.Time) (model.PageSnapshot, error)
+}
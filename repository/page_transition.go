@@ -0,0 +1,7 @@
+package repository
+
+import "github.com/gowool/pages/model"
+
+type PageTransition interface {
+	Repository[model.PageTransition, int64]
+}
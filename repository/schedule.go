@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// ScheduleCheckpoint stores the instant up to which the scheduler has processed the publish windows,
+// so the transitions missed while the application was down are replayed on the next start.
+type ScheduleCheckpoint interface {
+	Load(ctx context.Context) (time.Time, error)
+	Save(ctx context.Context, processed time.Time) error
+}
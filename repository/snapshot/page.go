@@ -0,0 +1,134 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gowool/cr"
+	"github.com/gowool/echox"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+// PageRepository serves the published snapshots to the visitors, while the editors and the requests
+// of the draft areas, like the admin api, read and write the drafts.
+// The pages without a snapshot are not found for the visitors.
+type PageRepository struct {
+	repository.Page
+	snapshots  repository.PageSnapshot
+	draftAreas []string
+}
+
+func NewPageRepository(inner repository.Page, snapshots repository.PageSnapshot, draftAreas ...string) PageRepository {
+	return PageRepository{
+		Page:       inner,
+		snapshots:  snapshots,
+		draftAreas: draftAreas,
+	}
+}
+
+func (r PageRepository) draft(ctx context.Context) bool {
+	return pages.CtxEditor(ctx) || slices.Contains(r.draftAreas, echox.CtxArea(ctx))
+}
+
+func (r PageRepository) Find(ctx context.Context, criteria *cr.Criteria) ([]model.Page, error) {
+	if r.draft(ctx) {
+		return r.Page.Find(ctx, criteria)
+	}
+
+	data, err := r.snapshots.Find(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	return internal.Map(data, served), nil
+}
+
+func (r PageRepository) FindAndCount(ctx context.Context, criteria *cr.Criteria) ([]model.Page, int, error) {
+	if r.draft(ctx) {
+		return r.Page.FindAndCount(ctx, criteria)
+	}
+
+	data, total, err := r.snapshots.FindAndCount(ctx, criteria)
+	if err != nil {
+		return nil, 0, err
+	}
+	return internal.Map(data, served), total, nil
+}
+
+func (r PageRepository) FindByID(ctx context.Context, id int64) (model.Page, error) {
+	if r.draft(ctx) {
+		return r.Page.FindByID(ctx, id)
+	}
+	return page(r.snapshots.FindByID(ctx, id))
+}
+
+func (r PageRepository) FindByParentID(ctx context.Context, parentID int64, now time.Time) ([]model.Page, error) {
+	if r.draft(ctx) {
+		return r.Page.FindByParentID(ctx, parentID, now)
+	}
+
+	data, err := r.snapshots.FindByParentID(ctx, parentID, now)
+	if err != nil {
+		return nil, err
+	}
+	return internal.Map(data, served), nil
+}
+
+// FindByPattern serves the drafts of the internal pages, like the error pages, until they are published,
+// as they are seeded by the system instead of the editors.
+func (r PageRepository) FindByPattern(ctx context.Context, siteID int64, pattern string, now time.Time) (model.Page, error) {
+	if r.draft(ctx) {
+		return r.Page.FindByPattern(ctx, siteID, pattern, now)
+	}
+
+	m, err := page(r.snapshots.FindByPattern(ctx, siteID, pattern, now))
+	if err != nil && strings.HasPrefix(pattern, model.PageInternalPrefix) && pages.IsOneOfNotFound(err) {
+		return r.Page.FindByPattern(ctx, siteID, pattern, now)
+	}
+	return m, err
+}
+
+func (r PageRepository) FindByAlias(ctx context.Context, siteID int64, alias string, now time.Time) (model.Page, error) {
+	if r.draft(ctx) {
+		return r.Page.FindByAlias(ctx, siteID, alias, now)
+	}
+	return page(r.snapshots.FindByAlias(ctx, siteID, alias, now))
+}
+
+func (r PageRepository) FindByURL(ctx context.Context, siteID int64, url string, now time.Time) (model.Page, error) {
+	if r.draft(ctx) {
+		return r.Page.FindByURL(ctx, siteID, url, now)
+	}
+	return page(r.snapshots.FindByURL(ctx, siteID, url, now))
+}
+
+// Delete unpublishes the deleted pages as well.
+func (r PageRepository) Delete(ctx context.Context, ids ...int64) error {
+	if err := r.Page.Delete(ctx, ids...); err != nil {
+		return err
+	}
+	if err := r.snapshots.Delete(ctx, ids...); err != nil && !pages.IsOneOfNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func served(s model.PageSnapshot) model.Page {
+	return s.ServedPage()
+}
+
+func page(s model.PageSnapshot, err error) (model.Page, error) {
+	if err != nil {
+		if pages.IsOneOfNotFound(err) && !errors.Is(err, pages.ErrPageNotFound) {
+			return model.Page{}, errors.Join(err, pages.ErrPageNotFound)
+		}
+		return model.Page{}, err
+	}
+	return s.ServedPage(), nil
+}
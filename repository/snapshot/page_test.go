@@ -0,0 +1,133 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages"
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func newTestPageRepository(t *testing.T) (PageRepository, memory.PageRepository, memory.PageSnapshotRepository) {
+	t.Helper()
+
+	ctx := context.Background()
+	inner := memory.NewPageRepository(pages.ErrPageNotFound)
+	snapshots := memory.NewPageSnapshotRepository(pages.ErrPageSnapshotNotFound)
+
+	published := time.Now().UTC().Add(-time.Hour)
+	for _, m := range []model.Page{
+		{Name: "Home", SiteID: 1, Pattern: model.PageCMS, URL: "/", Template: "home.html", Published: &published},
+		{Name: "Draft", SiteID: 1, Pattern: model.PageCMS, URL: "/draft", Published: &published},
+		{Name: "Not found", SiteID: 1, Pattern: model.PageInternalPrefix + "404", Published: &published},
+	} {
+		if err := inner.Create(ctx, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	home, _ := inner.FindByID(ctx, 1)
+	layout := model.Template{Name: "home.html", Content: "<h1>published</h1>"}
+	snapshot := model.NewPageSnapshot(home, &layout, "alice", published)
+	if err := snapshots.Create(ctx, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	// the draft of the home page changed after its publication
+	home.Title = "Changed"
+	if err := inner.Update(ctx, &home); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewPageRepository(inner, snapshots, "admin"), inner, snapshots
+}
+
+func TestPageRepositoryServesSnapshots(t *testing.T) {
+	repo, _, _ := newTestPageRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	m, err := repo.FindByURL(ctx, 1, "/", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Title == "Changed" {
+		t.Errorf("visitor got the draft")
+	}
+	if m.Template != model.PageSnapshotTemplatePrefix+"1" {
+		t.Errorf("template = %q, want the frozen template", m.Template)
+	}
+
+	if _, err = repo.FindByURL(ctx, 1, "/draft", now); !errors.Is(err, pages.ErrPageNotFound) {
+		t.Errorf("unpublished page err = %v, want %v", err, pages.ErrPageNotFound)
+	}
+
+	// the internal pages are served before their publication
+	if _, err = repo.FindByPattern(ctx, 1, model.PageInternalPrefix+"404", now); err != nil {
+		t.Errorf("internal page err = %v", err)
+	}
+	if _, err = repo.FindByPattern(ctx, 1, model.PageCMS+"x", now); err == nil {
+		t.Errorf("unknown pattern found")
+	}
+}
+
+func TestPageRepositoryServesDraftsToEditors(t *testing.T) {
+	repo, _, _ := newTestPageRepository(t)
+	ctx := pages.WithEditor(context.Background(), true)
+
+	m, err := repo.FindByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Title != "Changed" || m.Template != "home.html" {
+		t.Errorf("editor got %q with %q, want the draft", m.Title, m.Template)
+	}
+
+	if _, err = repo.FindByURL(ctx, 1, "/draft", time.Time{}); err != nil {
+		t.Errorf("editor err = %v", err)
+	}
+}
+
+func TestPageRepositoryDeleteUnpublishes(t *testing.T) {
+	repo, _, snapshots := newTestPageRepository(t)
+	ctx := context.Background()
+
+	// the page 2 has no snapshot
+	if err := repo.Delete(ctx, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if snapshots.Len() != 0 {
+		t.Errorf("snapshots = %d, want 0", snapshots.Len())
+	}
+}
+
+func TestTemplateRepositoryResolvesFrozenTemplates(t *testing.T) {
+	_, _, snapshots := newTestPageRepository(t)
+	ctx := context.Background()
+
+	templates := memory.NewTemplateRepository(sql.ErrNoRows)
+	if err := templates.Create(ctx, &model.Template{Name: "home.html", Content: "<h1>draft</h1>"}); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewTemplateRepository(templates, snapshots)
+
+	frozen, err := repo.FindByName(ctx, model.PageSnapshotTemplatePrefix+"1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frozen.Content != "<h1>published</h1>" {
+		t.Errorf("content = %q, want the frozen one", frozen.Content)
+	}
+
+	draft, err := repo.FindByName(ctx, "home.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if draft.Content != "<h1>draft</h1>" {
+		t.Errorf("content = %q, want the draft", draft.Content)
+	}
+}
@@ -0,0 +1,38 @@
+package snapshot
+
+import (
+	"context"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+// TemplateRepository resolves the templates frozen with the page snapshots by the names of the served pages.
+type TemplateRepository struct {
+	repository.Template
+	snapshots repository.PageSnapshot
+}
+
+func NewTemplateRepository(inner repository.Template, snapshots repository.PageSnapshot) TemplateRepository {
+	return TemplateRepository{
+		Template:  inner,
+		snapshots: snapshots,
+	}
+}
+
+func (r TemplateRepository) FindByName(ctx context.Context, name string) (model.Template, error) {
+	id, ok := model.PageSnapshotID(name)
+	if !ok {
+		return r.Template.FindByName(ctx, name)
+	}
+
+	s, err := r.snapshots.FindByID(ctx, id)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	if t, ok := s.ServedTemplate(); ok {
+		return t, nil
+	}
+	return r.Template.FindByName(ctx, s.Page.Template)
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "github.com/gowool/pages/model"
+
+type TemplateRevision interface {
+	Repository[model.TemplateRevision, int64]
+}
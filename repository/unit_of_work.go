@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	_ UnitOfWork = NopUnitOfWork{}
+	_ UnitOfWork = HookedUnitOfWork{}
+)
+
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NopUnitOfWork calls fn without a transaction, every change is saved on its own.
+// It is meant for the storages without transactions, the changes spanning several models,
+// like the URLs of a page subtree, are not atomic with it.
+type NopUnitOfWork struct{}
+
+func (NopUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type afterCommitKey struct{}
+
+type afterCommit struct {
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+func (h *afterCommit) add(hooks ...func(ctx context.Context)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hooks = append(h.hooks, hooks...)
+}
+
+// AfterCommit calls fn once the HookedUnitOfWork of the context is committed and drops it on rollback.
+// Without a HookedUnitOfWork the change is saved already, so fn is called at once.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if h, ok := ctx.Value(afterCommitKey{}).(*afterCommit); ok {
+		h.add(fn)
+		return
+	}
+	fn(ctx)
+}
+
+// HookedUnitOfWork calls the functions registered by AfterCommit once its outermost unit is committed,
+// with the context of that unit. The functions registered by a failed nested unit are dropped with it.
+type HookedUnitOfWork struct {
+	UnitOfWork
+}
+
+func NewHookedUnitOfWork(inner UnitOfWork) HookedUnitOfWork {
+	if inner == nil {
+		panic("unit of work is not specified")
+	}
+	return HookedUnitOfWork{UnitOfWork: inner}
+}
+
+func (u HookedUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent, nested := ctx.Value(afterCommitKey{}).(*afterCommit)
+
+	h := &afterCommit{}
+	if err := u.UnitOfWork.Do(context.WithValue(ctx, afterCommitKey{}, h), fn); err != nil {
+		return err
+	}
+
+	if nested {
+		parent.add(h.hooks...)
+		return nil
+	}
+	for _, hook := range h.hooks {
+		hook(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestHookedUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	uow := NewHookedUnitOfWork(NopUnitOfWork{})
+	errRollback := errors.New("rollback")
+
+	var called []string
+	hook := func(name string) func(context.Context) {
+		return func(context.Context) { called = append(called, name) }
+	}
+
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, hook("outer"))
+
+		if err := uow.Do(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, hook("nested"))
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		_ = uow.Do(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, hook("failed"))
+			return errRollback
+		})
+
+		if len(called) > 0 {
+			t.Errorf("hooks called before commit: %v", called)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(called, []string{"outer", "nested"}) {
+		t.Errorf("called = %v, want [outer nested]", called)
+	}
+
+	called = nil
+	err = uow.Do(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, hook("rolled back"))
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("err = %v, want %v", err, errRollback)
+	}
+	if len(called) > 0 {
+		t.Errorf("called = %v after rollback", called)
+	}
+
+	AfterCommit(ctx, hook("no unit"))
+	if !slices.Equal(called, []string{"no unit"}) {
+		t.Errorf("called = %v, want the hook called at once", called)
+	}
+}
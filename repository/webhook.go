@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/gowool/pages/model"
+
+type Webhook interface {
+	Repository[model.Webhook, int64]
+}
+
+type WebhookDelivery interface {
+	Repository[model.WebhookDelivery, int64]
+}
@@ -0,0 +1,332 @@
+package pages
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gowool/cr"
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages/repository"
+)
+
+const (
+	ScheduleEntitySite = "site"
+	ScheduleEntityPage = "page"
+
+	ScheduleActionPublished = "published"
+	ScheduleActionExpired   = "expired"
+)
+
+var (
+	_ Scheduler       = (*DefaultScheduler)(nil)
+	_ EventSubscriber = (*ScheduleRescan)(nil)
+)
+
+// ScheduleEvent is emitted when the publish window of a site or a page opens or closes.
+type ScheduleEvent struct {
+	Entity string
+	Action string
+	ID     int64
+	SiteID int64
+	At     time.Time
+}
+
+type ScheduleListener interface {
+	OnSchedule(ctx context.Context, event ScheduleEvent) error
+}
+
+type Scheduler interface {
+	// Start scans the upcoming publications and expirations and runs the scheduler until Stop.
+	Start(ctx context.Context) error
+
+	// Stop stops the scheduler and waits for it to return.
+	Stop(ctx context.Context) error
+
+	// Rescan makes the running scheduler scan the sites and the pages again,
+	// so the changed publish windows are tracked before the next periodic scan.
+	Rescan()
+}
+
+// ScheduleRescan makes the scheduler scan the sites and the pages again when they change.
+// It is shared by the scheduler and the event dispatcher, so they do not depend on each other.
+type ScheduleRescan struct {
+	c chan struct{}
+}
+
+func NewScheduleRescan() *ScheduleRescan {
+	return &ScheduleRescan{c: make(chan struct{}, 1)}
+}
+
+// Rescan signals the scheduler without blocking, the pending signals are merged.
+func (r *ScheduleRescan) Rescan() {
+	select {
+	case r.c <- struct{}{}:
+	default:
+	}
+}
+
+func (r *ScheduleRescan) HandleEvent(_ context.Context, event Event) error {
+	meta := event.Meta()
+	if meta.Entity != EventEntitySite && meta.Entity != EventEntityPage {
+		return nil
+	}
+
+	// the publications replace the snapshots, so they change the scanned windows as well,
+	// the ones fired by the scheduler itself only cost a rescan
+	switch meta.Action {
+	case EventActionCreated, EventActionUpdated, EventActionDeleted, EventActionPublished, EventActionExpired:
+		r.Rescan()
+	}
+	return nil
+}
+
+type DefaultScheduler struct {
+	siteRepo     repository.Site
+	snapshotRepo repository.PageSnapshot
+	checkpoint   repository.ScheduleCheckpoint
+	cache        Cache
+	listeners    []ScheduleListener
+	rescan       *ScheduleRescan
+	logger       *zap.Logger
+
+	// Interval is the period of the rescans.
+	Interval time.Duration
+
+	// Lookback is the period before the start whose transitions are replayed
+	// when there is no checkpoint, covering the ones missed while the application was down.
+	Lookback time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+}
+
+func NewDefaultScheduler(
+	siteRepo repository.Site,
+	snapshotRepo repository.PageSnapshot,
+	checkpoint repository.ScheduleCheckpoint,
+	cache Cache,
+	listeners []ScheduleListener,
+	rescan *ScheduleRescan,
+	logger *zap.Logger,
+) *DefaultScheduler {
+	if siteRepo == nil {
+		panic("site repository is not specified")
+	}
+	if snapshotRepo == nil {
+		panic("page snapshot repository is not specified")
+	}
+	if rescan == nil {
+		rescan = NewScheduleRescan()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &DefaultScheduler{
+		siteRepo:     siteRepo,
+		snapshotRepo: snapshotRepo,
+		checkpoint:   checkpoint,
+		cache:        cache,
+		listeners:    listeners,
+		rescan:       rescan,
+		logger:       logger,
+		Interval:     5 * time.Minute,
+		Lookback:     time.Hour,
+	}
+}
+
+func (s *DefaultScheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return nil
+	}
+
+	since, err := s.since(ctx)
+	if err != nil {
+		return err
+	}
+
+	queue, err := s.scan(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.run(runCtx, since, queue)
+	return nil
+}
+
+func (s *DefaultScheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	s.cancel = nil
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *DefaultScheduler) Rescan() {
+	s.rescan.Rescan()
+}
+
+// since returns the checkpoint, or the start of the lookback when there is none.
+func (s *DefaultScheduler) since(ctx context.Context) (time.Time, error) {
+	if s.checkpoint != nil {
+		processed, err := s.checkpoint.Load(ctx)
+		if err != nil && !IsOneOfNotFound(err) {
+			return time.Time{}, err
+		}
+		if !processed.IsZero() {
+			return processed.UTC(), nil
+		}
+	}
+	return time.Now().UTC().Add(-s.Lookback), nil
+}
+
+func (s *DefaultScheduler) run(ctx context.Context, since time.Time, queue []ScheduleEvent) {
+	defer close(s.done)
+
+	next := time.Now().Add(s.Interval)
+
+	for {
+		now := time.Now().UTC()
+		for len(queue) > 0 && !queue[0].At.After(now) {
+			s.fire(ctx, queue[0])
+			queue = queue[1:]
+		}
+		since = now
+
+		if s.checkpoint != nil {
+			if err := s.checkpoint.Save(ctx, now); err != nil {
+				s.logger.Error("scheduler: checkpoint failed", zap.Error(err))
+			}
+		}
+
+		wait := time.Until(next)
+		if len(queue) > 0 {
+			wait = min(wait, queue[0].At.Sub(now))
+		}
+		timer := time.NewTimer(max(wait, 0))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.rescan.c:
+			timer.Stop()
+		case <-timer.C:
+			if time.Now().Before(next) {
+				continue
+			}
+		}
+
+		next = time.Now().Add(s.Interval)
+		if scanned, err := s.scan(ctx, since); err == nil {
+			queue = scanned
+		} else {
+			s.logger.Error("scheduler: scan failed", zap.Error(err))
+		}
+	}
+}
+
+// scan returns the transitions after since sorted by time.
+// The pages are scanned in their published snapshots, the drafts are not served to the visitors.
+func (s *DefaultScheduler) scan(ctx context.Context, since time.Time) ([]ScheduleEvent, error) {
+	criteria := cr.New().SetFilter(cr.Filter{
+		Operator: cr.OpOR,
+		Conditions: []any{
+			cr.Condition{Column: "published", Operator: cr.OpGt, Value: since},
+			cr.Condition{Column: "expired", Operator: cr.OpGt, Value: since},
+		},
+	})
+
+	sites, err := s.siteRepo.Find(WithEditor(ctx, true), criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.snapshotRepo.Find(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue []ScheduleEvent
+	add := func(entity string, id, siteID int64, published, expired *time.Time) {
+		if published != nil && published.After(since) {
+			queue = append(queue, ScheduleEvent{Entity: entity, Action: ScheduleActionPublished, ID: id, SiteID: siteID, At: published.UTC()})
+		}
+		if expired != nil && expired.After(since) {
+			queue = append(queue, ScheduleEvent{Entity: entity, Action: ScheduleActionExpired, ID: id, SiteID: siteID, At: expired.UTC()})
+		}
+	}
+
+	for _, site := range sites {
+		add(ScheduleEntitySite, site.ID, site.ID, site.Published, site.Expired)
+	}
+	for _, snapshot := range snapshots {
+		add(ScheduleEntityPage, snapshot.ID, snapshot.SiteID, &snapshot.Published, snapshot.Expired)
+	}
+
+	slices.SortStableFunc(queue, func(a, b ScheduleEvent) int {
+		return a.At.Compare(b.At)
+	})
+	return queue, nil
+}
+
+func (s *DefaultScheduler) fire(ctx context.Context, event ScheduleEvent) {
+	s.logger.Debug("scheduler: transition",
+		zap.String("entity", event.Entity),
+		zap.String("action", event.Action),
+		zap.Int64("id", event.ID),
+		zap.Time("at", event.At))
+
+	if s.cache != nil {
+		for _, tag := range s.tags(ctx, event) {
+			_ = s.cache.DelByTag(ctx, tag)
+		}
+	}
+
+	for _, listener := range s.listeners {
+		if err := listener.OnSchedule(ctx, event); err != nil {
+			s.logger.Error("scheduler: listener failed", zap.Int64("id", event.ID), zap.Error(err))
+		}
+	}
+}
+
+func (s *DefaultScheduler) tags(ctx context.Context, event ScheduleEvent) []string {
+	if event.Entity == ScheduleEntitySite {
+		// the pages are tagged with their sites as well
+		return []string{
+			CacheTag(CachePrefixSite, event.ID),
+			SiteHostsCacheTag(),
+			MenuFragmentSiteCacheTag(event.ID),
+		}
+	}
+
+	tags := []string{
+		CacheTag(CachePrefixPage, event.ID),
+		MenuFragmentSiteCacheTag(event.SiteID),
+	}
+	if snapshot, err := s.snapshotRepo.FindByID(ctx, event.ID); err == nil && snapshot.ParentID != nil {
+		// the children of the parent are cached as one list
+		tags = append(tags, CacheTag(CachePrefixPage, *snapshot.ParentID))
+	}
+	return tags
+}
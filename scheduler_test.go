@@ -0,0 +1,232 @@
+package pages
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type testSiteRepository struct {
+	*memory.Repository[model.Site]
+}
+
+func (testSiteRepository) FindByHosts(context.Context, []string, time.Time) ([]model.Site, error) {
+	return nil, nil
+}
+
+type testCheckpoint struct {
+	mu        sync.Mutex
+	processed time.Time
+}
+
+func (c *testCheckpoint) Load(context.Context) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.processed, nil
+}
+
+func (c *testCheckpoint) Save(_ context.Context, processed time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.processed = processed
+	return nil
+}
+
+type testScheduleListener chan ScheduleEvent
+
+func (l testScheduleListener) OnSchedule(_ context.Context, event ScheduleEvent) error {
+	l <- event
+	return nil
+}
+
+func (l testScheduleListener) wait(t *testing.T, timeout time.Duration) (ScheduleEvent, bool) {
+	t.Helper()
+
+	select {
+	case event := <-l:
+		return event, true
+	case <-time.After(timeout):
+		return ScheduleEvent{}, false
+	}
+}
+
+func newTestScheduler(t *testing.T, checkpoint *testCheckpoint, rescan *ScheduleRescan) (*DefaultScheduler, memory.PageSnapshotRepository, testScheduleListener) {
+	t.Helper()
+
+	snapshotRepo := memory.NewPageSnapshotRepository(ErrPageSnapshotNotFound)
+	listener := make(testScheduleListener, 10)
+
+	var cp repository.ScheduleCheckpoint
+	if checkpoint != nil {
+		cp = checkpoint
+	}
+
+	s := NewDefaultScheduler(
+		testSiteRepository{memory.NewRepository[model.Site](ErrSiteNotFound)},
+		snapshotRepo,
+		cp,
+		memory.NewCache(),
+		[]ScheduleListener{listener},
+		rescan,
+		nil,
+	)
+	s.Interval = time.Hour
+	t.Cleanup(func() { _ = s.Stop(context.Background()) })
+	return s, snapshotRepo, listener
+}
+
+// publishTestPage stores the snapshot of the page, the way the publisher does.
+func publishTestPage(t *testing.T, snapshotRepo memory.PageSnapshotRepository, m *model.Page) {
+	t.Helper()
+
+	m.ID = int64(snapshotRepo.Len() + 1)
+	snapshot := model.NewPageSnapshot(*m, nil, "", *m.Published)
+	if err := snapshotRepo.Create(context.Background(), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSchedulerReplaysFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	checkpoint := &testCheckpoint{processed: time.Now().UTC().Add(-3 * time.Hour)}
+	s, snapshotRepo, listener := newTestScheduler(t, checkpoint, nil)
+
+	// published while the application was down, before the lookback
+	published := time.Now().UTC().Add(-2 * time.Hour)
+	m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS, Published: &published}
+	publishTestPage(t, snapshotRepo, &m)
+
+	start := time.Now().UTC()
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	event, ok := listener.wait(t, time.Second)
+	if !ok {
+		t.Fatal("the missed publication was not replayed")
+	}
+	if event.ID != m.ID || event.Action != ScheduleActionPublished {
+		t.Errorf("event = %+v", event)
+	}
+
+	if err := s.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if processed, _ := checkpoint.Load(ctx); processed.Before(start) {
+		t.Errorf("checkpoint = %v, want after %v", processed, start)
+	}
+}
+
+func TestSchedulerLookbackWithoutCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	s, snapshotRepo, listener := newTestScheduler(t, &testCheckpoint{}, nil)
+	s.Lookback = time.Hour
+
+	for _, ago := range []time.Duration{2 * time.Hour, 30 * time.Minute} {
+		published := time.Now().UTC().Add(-ago)
+		m := model.Page{SiteID: 1, Name: "Page", Pattern: model.PageCMS, Published: &published}
+		publishTestPage(t, snapshotRepo, &m)
+	}
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	event, ok := listener.wait(t, time.Second)
+	if !ok || event.ID != 2 {
+		t.Fatalf("event = %+v, want the publication within the lookback", event)
+	}
+	if event, ok = listener.wait(t, 100*time.Millisecond); ok {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestSchedulerRescansOnPageEvents(t *testing.T) {
+	ctx := context.Background()
+	rescan := NewScheduleRescan()
+	s, snapshotRepo, listener := newTestScheduler(t, nil, rescan)
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	published := time.Now().UTC().Add(200 * time.Millisecond)
+	m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS, Published: &published}
+	publishTestPage(t, snapshotRepo, &m)
+
+	// the events of the other entities are ignored
+	_ = rescan.HandleEvent(ctx, NewEntityEvent[model.Menu](ctx, EventEntityMenu, EventActionCreated, 1, nil, nil, nil))
+	if event, ok := listener.wait(t, 400*time.Millisecond); ok {
+		t.Fatalf("unexpected event %+v before the rescan", event)
+	}
+
+	_ = rescan.HandleEvent(ctx, NewEntityEvent(ctx, EventEntityPage, EventActionCreated, m.ID, &m.SiteID, nil, &m))
+
+	event, ok := listener.wait(t, time.Second)
+	if !ok {
+		t.Fatal("the page was not rescanned")
+	}
+	if event.ID != m.ID || event.Action != ScheduleActionPublished {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestSchedulerScansPublishedSnapshots(t *testing.T) {
+	ctx := context.Background()
+	pageRepo := memory.NewPageRepository(ErrPageNotFound)
+	snapshotRepo := memory.NewPageSnapshotRepository(ErrPageSnapshotNotFound)
+	listener := make(testScheduleListener, 10)
+
+	s := NewDefaultScheduler(
+		testSiteRepository{memory.NewRepository[model.Site](ErrSiteNotFound)},
+		snapshotRepo,
+		nil,
+		memory.NewCache(),
+		[]ScheduleListener{listener},
+		nil,
+		nil,
+	)
+	s.Interval = time.Hour
+	t.Cleanup(func() { _ = s.Stop(context.Background()) })
+
+	// the visitors see the page until its snapshot expires, it was published before the lookback
+	published := time.Now().UTC().Add(-2 * time.Hour)
+	expired := time.Now().UTC().Add(200 * time.Millisecond)
+	m := model.Page{SiteID: 1, Name: "About", Pattern: model.PageCMS, Published: &published, Expired: &expired}
+	if err := pageRepo.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := model.NewPageSnapshot(m, nil, "", published)
+	if err := snapshotRepo.Create(ctx, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	// the draft has another window, which is not served before it is published
+	draftPublished := time.Now().UTC().Add(100 * time.Millisecond)
+	m.Published, m.Expired = &draftPublished, nil
+	if err := pageRepo.Update(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	event, ok := listener.wait(t, time.Second)
+	if !ok {
+		t.Fatal("the expiration of the snapshot was not scheduled")
+	}
+	if event.ID != m.ID || event.Action != ScheduleActionExpired || !event.At.Equal(expired) {
+		t.Errorf("event = %+v, want the snapshot expired at %v", event, expired)
+	}
+	if event, ok = listener.wait(t, 200*time.Millisecond); ok {
+		t.Errorf("unexpected event %+v of the draft", event)
+	}
+}
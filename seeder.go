@@ -50,6 +50,9 @@ func NewDefaultSeeder(siteRepository repository.Site, pageRepository repository.
 }
 
 func (s *DefaultSeeder) Boot(ctx context.Context) error {
+	// the seeded pages are looked up among the drafts, not the published snapshots
+	ctx = WithEditor(ctx, true)
+
 	sites, err := s.FindOrCreateLocalhost(ctx)
 	if err != nil {
 		return err
@@ -103,6 +106,7 @@ func (s *DefaultSeeder) CreateErrorPages(ctx context.Context, site model.Site) e
 
 		now := time.Now().UTC()
 		page.Published = &now
+		page.State = model.WorkflowPublished
 		page.SiteID = site.ID
 		if err = s.pageRepository.Create(ctx, &page); err != nil {
 			return err
@@ -126,6 +130,7 @@ func (s *DefaultSeeder) InternalCreatePage(ctx context.Context, site model.Site)
 		Name:      "Create page",
 		Template:  "@error/page_create.gohtml",
 		Published: &now,
+		State:     model.WorkflowPublished,
 	}); err != nil {
 		return err
 	}
@@ -0,0 +1,208 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gowool/cr"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+const (
+	TemplateRefExtends     = "extends"
+	TemplateRefTemplate    = "template"
+	TemplateRefBlock       = "block"
+	TemplateRefMenu        = "menu"
+	TemplateRefBreadcrumbs = "breadcrumbs"
+)
+
+var (
+	_ TemplateAnalyzer = (*DefaultTemplateAnalyzer)(nil)
+
+	reTemplateDefine = regexp.MustCompile(`{{-?\s*define\s+"([^"]+)"`)
+	reTemplateRefs   = map[string]*regexp.Regexp{
+		TemplateRefExtends:     regexp.MustCompile(`{{-?\s*extends\s+"([^"]+)"`),
+		TemplateRefTemplate:    regexp.MustCompile(`{{-?\s*template\s+"([^"]+)"`),
+		TemplateRefBlock:       regexp.MustCompile(`{{-?\s*block\s+"([^"]+)"`),
+		TemplateRefMenu:        regexp.MustCompile(`\bmenu\s+\S+\s+"[^"]*"\s+"([^"]+)"`),
+		TemplateRefBreadcrumbs: regexp.MustCompile(`\bbreadcrumbs(?:_menu\s+\S+\s+"[^"]*"|\s+\S+)\s+"([^"]+)"`),
+	}
+)
+
+type TemplateReference struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+	Kind string `json:"kind" yaml:"kind" enum:"extends,template,block,menu,breadcrumbs"`
+	Line int    `json:"line" yaml:"line"`
+
+	// Missing is set when the referenced template does not exist.
+	Missing bool `json:"missing,omitempty" yaml:"missing,omitempty"`
+}
+
+type TemplateAnalyzer interface {
+	// Dependencies returns the templates referenced by the template.
+	Dependencies(ctx context.Context, name string) ([]TemplateReference, error)
+
+	// Dependents returns the references of the other templates to the template.
+	Dependents(ctx context.Context, name string) ([]TemplateReference, error)
+
+	// Pages returns the pages rendered with the template.
+	Pages(ctx context.Context, name string) ([]model.Page, error)
+
+	// CanDelete returns *TemplateInUseError when the templates are referenced by the pages
+	// or the templates out of the deleted ones.
+	CanDelete(ctx context.Context, ids ...int64) error
+}
+
+type DefaultTemplateAnalyzer struct {
+	templateRepo repository.Template
+	pageRepo     repository.Page
+}
+
+func NewDefaultTemplateAnalyzer(templateRepo repository.Template, pageRepo repository.Page) *DefaultTemplateAnalyzer {
+	if templateRepo == nil {
+		panic("template repository is not specified")
+	}
+	if pageRepo == nil {
+		panic("page repository is not specified")
+	}
+	return &DefaultTemplateAnalyzer{
+		templateRepo: templateRepo,
+		pageRepo:     pageRepo,
+	}
+}
+
+func (a *DefaultTemplateAnalyzer) Dependencies(ctx context.Context, name string) ([]TemplateReference, error) {
+	m, err := a.templateRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]TemplateReference, 0)
+	for _, ref := range TemplateReferences(m) {
+		if _, err = a.templateRepo.FindByName(ctx, ref.To); err != nil {
+			if !IsOneOfNotFound(err) {
+				return nil, err
+			}
+			// the template and block references mostly name the blocks of the extended layout
+			if ref.Kind == TemplateRefTemplate || ref.Kind == TemplateRefBlock {
+				continue
+			}
+			ref.Missing = true
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (a *DefaultTemplateAnalyzer) Dependents(ctx context.Context, name string) ([]TemplateReference, error) {
+	templates, err := a.templateRepo.Find(ctx, cr.New())
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]TemplateReference, 0)
+	for _, m := range templates {
+		if m.Name == name {
+			continue
+		}
+		for _, ref := range TemplateReferences(m) {
+			if ref.To == name {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs, nil
+}
+
+func (a *DefaultTemplateAnalyzer) Pages(ctx context.Context, name string) ([]model.Page, error) {
+	return a.pageRepo.Find(ctx, cr.New().SetFilter(cr.Filter{
+		Conditions: []any{cr.Condition{Column: "template", Operator: cr.OpEqual, Value: name}},
+	}))
+}
+
+func (a *DefaultTemplateAnalyzer) CanDelete(ctx context.Context, ids ...int64) error {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		m, err := a.templateRepo.FindByID(ctx, id)
+		if err != nil {
+			if IsOneOfNotFound(err) {
+				continue
+			}
+			return err
+		}
+		names = append(names, m.Name)
+	}
+
+	for _, name := range names {
+		refs, err := a.Dependents(ctx, name)
+		if err != nil {
+			return err
+		}
+		refs = slices.DeleteFunc(refs, func(ref TemplateReference) bool {
+			return slices.Contains(names, ref.From)
+		})
+
+		pages, err := a.Pages(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		if len(refs) > 0 || len(pages) > 0 {
+			return &TemplateInUseError{Name: name, References: refs, Pages: pages}
+		}
+	}
+	return nil
+}
+
+// TemplateReferences returns the references found in the content of the template.
+// The names defined by the template itself are skipped.
+func TemplateReferences(m model.Template) []TemplateReference {
+	defined := make(map[string]struct{})
+	for _, match := range reTemplateDefine.FindAllStringSubmatch(m.Content, -1) {
+		defined[match[1]] = struct{}{}
+	}
+
+	var refs []TemplateReference
+	for kind, re := range reTemplateRefs {
+		for _, loc := range re.FindAllStringSubmatchIndex(m.Content, -1) {
+			to := m.Content[loc[2]:loc[3]]
+			if _, ok := defined[to]; ok || to == m.Name {
+				continue
+			}
+			refs = append(refs, TemplateReference{
+				From: m.Name,
+				To:   to,
+				Kind: kind,
+				Line: strings.Count(m.Content[:loc[0]], "\n") + 1,
+			})
+		}
+	}
+
+	slices.SortFunc(refs, func(a, b TemplateReference) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return strings.Compare(a.To, b.To)
+	})
+	return refs
+}
+
+type TemplateInUseError struct {
+	Name       string
+	References []TemplateReference
+	Pages      []model.Page
+}
+
+func (e *TemplateInUseError) Error() string {
+	return fmt.Sprintf("template %q is used by %d template reference(s) and %d page(s)", e.Name, len(e.References), len(e.Pages))
+}
+
+func (e *TemplateInUseError) Unwrap() error {
+	return ErrTemplateInUse
+}
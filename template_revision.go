@@ -0,0 +1,182 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+var _ TemplateRevisions = (*DefaultTemplateRevisions)(nil)
+
+type TemplateRevisions interface {
+	// Save stores the template with the saver and records its revision in one unit of work.
+	// The author of the revision is the context actor.
+	Save(ctx context.Context, m *model.Template, message string, saver func(context.Context, *model.Template) error) error
+
+	// Diff returns the unified diff of the contents of the revisions.
+	Diff(ctx context.Context, fromID, toID int64) (string, error)
+
+	// Restore sets the content of the template to the one of the revision, records it as a new revision
+	// and purges the caches of the template, its dependents and the pages rendered with them.
+	// The content is validated first, it returns *TemplateSyntaxError when it does not compile anymore.
+	Restore(ctx context.Context, id int64, message string) (model.Template, error)
+}
+
+type DefaultTemplateRevisions struct {
+	templateRepo repository.Template
+	revisionRepo repository.TemplateRevision
+	analyzer     TemplateAnalyzer
+	validator    TemplateValidator
+	uow          repository.UnitOfWork
+	cache        Cache
+}
+
+func NewDefaultTemplateRevisions(
+	templateRepo repository.Template,
+	revisionRepo repository.TemplateRevision,
+	analyzer TemplateAnalyzer,
+	validator TemplateValidator,
+	uow repository.UnitOfWork,
+	cache Cache,
+) *DefaultTemplateRevisions {
+	if templateRepo == nil {
+		panic("template repository is not specified")
+	}
+	if revisionRepo == nil {
+		panic("template revision repository is not specified")
+	}
+	if analyzer == nil {
+		panic("template analyzer is not specified")
+	}
+	if validator == nil {
+		panic("template validator is not specified")
+	}
+	if uow == nil {
+		panic("unit of work is not specified")
+	}
+	return &DefaultTemplateRevisions{
+		templateRepo: templateRepo,
+		revisionRepo: revisionRepo,
+		analyzer:     analyzer,
+		validator:    validator,
+		uow:          uow,
+		cache:        cache,
+	}
+}
+
+func (r *DefaultTemplateRevisions) Save(ctx context.Context, m *model.Template, message string, saver func(context.Context, *model.Template) error) error {
+	return r.uow.Do(ctx, func(ctx context.Context) error {
+		if err := saver(ctx, m); err != nil {
+			return err
+		}
+
+		// the templates of the file system are versioned with the code
+		if m.Type == model.TemplateFS {
+			return nil
+		}
+
+		return r.revisionRepo.Create(ctx, &model.TemplateRevision{
+			TemplateID: m.ID,
+			Name:       m.Name,
+			Content:    m.Content,
+			Author:     CtxActor(ctx),
+			Message:    message,
+			Created:    time.Now().UTC(),
+		})
+	})
+}
+
+func (r *DefaultTemplateRevisions) Diff(ctx context.Context, fromID, toID int64) (string, error) {
+	from, err := r.revisionRepo.FindByID(ctx, fromID)
+	if err != nil {
+		return "", err
+	}
+
+	to, err := r.revisionRepo.FindByID(ctx, toID)
+	if err != nil {
+		return "", err
+	}
+
+	if from.TemplateID != to.TemplateID {
+		return "", &ValidationError{Errors: []FieldError{{
+			Field:   "to",
+			Value:   toID,
+			Message: fmt.Sprintf("revision belongs to template %d instead of %d", to.TemplateID, from.TemplateID),
+		}}}
+	}
+
+	return internal.UnifiedDiff(from.String(), to.String(), from.Content, to.Content, 3), nil
+}
+
+func (r *DefaultTemplateRevisions) Restore(ctx context.Context, id int64, message string) (model.Template, error) {
+	revision, err := r.revisionRepo.FindByID(ctx, id)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	m, err := r.templateRepo.FindByID(ctx, revision.TemplateID)
+	if err != nil {
+		return model.Template{}, err
+	}
+
+	// the revision may predate a change of the templates it depends on
+	if err = r.validator.Validate(ctx, m.Name, revision.Content); err != nil {
+		return model.Template{}, err
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Restore revision %d", revision.ID)
+	}
+	m.Content = revision.Content
+
+	if err = r.Save(ctx, &m, message, r.templateRepo.Update); err != nil {
+		return model.Template{}, err
+	}
+
+	r.purge(ctx, m)
+	return m, nil
+}
+
+func (r *DefaultTemplateRevisions) purge(ctx context.Context, m model.Template) {
+	if r.cache == nil {
+		return
+	}
+
+	_ = r.cache.DelByTag(ctx, CacheTag(CachePrefixTemplate, m.ID))
+	// the rendered menus depend on their templates
+	_ = r.cache.DelByTag(ctx, MenuFragmentNodesCacheTag())
+
+	visited := map[string]struct{}{}
+	names := []string{m.Name}
+
+	for len(names) > 0 {
+		name := names[0]
+		names = names[1:]
+
+		if _, ok := visited[name]; ok {
+			continue
+		}
+		visited[name] = struct{}{}
+
+		if pages, err := r.analyzer.Pages(ctx, name); err == nil {
+			for _, page := range pages {
+				_ = r.cache.DelByTag(ctx, CacheTag(CachePrefixPage, page.ID))
+			}
+		}
+
+		refs, err := r.analyzer.Dependents(ctx, name)
+		if err != nil {
+			continue
+		}
+		for _, ref := range refs {
+			if dependent, err := r.templateRepo.FindByName(ctx, ref.From); err == nil {
+				_ = r.cache.DelByTag(ctx, CacheTag(CachePrefixTemplate, dependent.ID))
+			}
+			names = append(names, ref.From)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package pages
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func newTestTemplateRevisions(t *testing.T) (*DefaultTemplateRevisions, memory.TemplateRepository, *memory.Repository[model.TemplateRevision]) {
+	t.Helper()
+
+	templateRepo := memory.NewTemplateRepository(sql.ErrNoRows)
+	revisionRepo := memory.NewRepository[model.TemplateRevision](sql.ErrNoRows)
+	pageRepo := memory.NewPageRepository(sql.ErrNoRows)
+
+	revisions := NewDefaultTemplateRevisions(
+		templateRepo,
+		revisionRepo,
+		NewDefaultTemplateAnalyzer(templateRepo, pageRepo),
+		NewDefaultTemplateValidator(templateRepo, nil),
+		&memory.UnitOfWork{},
+		memory.NewCache(),
+	)
+	return revisions, templateRepo, revisionRepo
+}
+
+func TestTemplateRevisionsRestore(t *testing.T) {
+	ctx := context.Background()
+	revisions, templateRepo, revisionRepo := newTestTemplateRevisions(t)
+
+	m := model.Template{Name: "page.html", Content: "<p>v1</p>", Type: model.TemplateDB}
+	if err := revisions.Save(ctx, &m, "", templateRepo.Create); err != nil {
+		t.Fatal(err)
+	}
+	m.Content = "<p>v2</p>"
+	if err := revisions.Save(ctx, &m, "", templateRepo.Update); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := revisions.Restore(ctx, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Content != "<p>v1</p>" {
+		t.Errorf("content = %q, want %q", restored.Content, "<p>v1</p>")
+	}
+	if revisionRepo.Len() != 3 {
+		t.Errorf("revisions = %d, want 3", revisionRepo.Len())
+	}
+	last, _ := revisionRepo.FindByID(ctx, 3)
+	if last.Message != "Restore revision 1" {
+		t.Errorf("message = %q", last.Message)
+	}
+}
+
+func TestTemplateRevisionsRestoreInvalid(t *testing.T) {
+	ctx := context.Background()
+	revisions, templateRepo, revisionRepo := newTestTemplateRevisions(t)
+
+	// the revision extended a layout which has been deleted since
+	m := model.Template{Name: "page.html", Content: "<p>v1</p>", Type: model.TemplateDB}
+	if err := templateRepo.Create(ctx, &m); err != nil {
+		t.Fatal(err)
+	}
+	if err := revisionRepo.Create(ctx, &model.TemplateRevision{
+		TemplateID: m.ID,
+		Name:       m.Name,
+		Content:    `{{ extends "layout.html" }}`,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := revisions.Restore(ctx, 1, "")
+
+	var syntaxErr *TemplateSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("err = %v, want *TemplateSyntaxError", err)
+	}
+	current, _ := templateRepo.FindByID(ctx, m.ID)
+	if current.Content != "<p>v1</p>" {
+		t.Errorf("content = %q, the invalid revision was restored", current.Content)
+	}
+	if revisionRepo.Len() != 1 {
+		t.Errorf("revisions = %d, want 1", revisionRepo.Len())
+	}
+}
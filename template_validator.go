@@ -0,0 +1,180 @@
+package pages
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gowool/theme"
+
+	"github.com/gowool/pages/repository"
+)
+
+var (
+	_ TemplateValidator = (*DefaultTemplateValidator)(nil)
+
+	reExtends     = regexp.MustCompile(`^\s*{{-?\s*extends\s+"([^"]+)"\s*-?}}`)
+	reParseError  = regexp.MustCompile(`^template: (.+?):(\d+):(?:(\d+):)? (.*)$`)
+	reQuotedToken = regexp.MustCompile(`"([^"]+)"|<([^>]+)>`)
+)
+
+type TemplateValidator interface {
+	// Validate parses the content of the template with the theme func maps resolving its extends chain.
+	// It returns *TemplateSyntaxError on failure.
+	Validate(ctx context.Context, name, content string) error
+}
+
+// DefaultTemplateValidator checks what the parser checks: the syntax, the extends chain
+// and that every called func is defined. It never executes the template, so the funcs
+// depending on the theme or on the request, e.g. menu, breadcrumbs and the page funcs,
+// are stubs: they are looked up by name and never called, their output is not validated.
+type DefaultTemplateValidator struct {
+	templateRepo repository.Template
+	funcMap      template.FuncMap
+}
+
+func NewDefaultTemplateValidator(templateRepo repository.Template, funcMaps []theme.FuncMap) *DefaultTemplateValidator {
+	if templateRepo == nil {
+		panic("template repository is not specified")
+	}
+
+	// the func maps get a nil theme, they must not use it before their funcs are called
+	funcMap := template.FuncMap{}
+	for _, fm := range funcMaps {
+		maps.Copy(funcMap, fm(nil))
+	}
+
+	return &DefaultTemplateValidator{
+		templateRepo: templateRepo,
+		funcMap:      funcMap,
+	}
+}
+
+func (v *DefaultTemplateValidator) Validate(ctx context.Context, name, content string) error {
+	type source struct {
+		name    string
+		content string
+	}
+
+	chain := []source{{name: name, content: content}}
+	visited := map[string]struct{}{name: {}}
+
+	for current := chain[0]; ; {
+		parent, loc := extendsOf(current.content)
+		if parent == "" {
+			break
+		}
+
+		if _, ok := visited[parent]; ok {
+			return &TemplateSyntaxError{
+				Name:    current.name,
+				Line:    1,
+				Column:  loc + 1,
+				Message: fmt.Sprintf("template %q extends itself", parent),
+			}
+		}
+		visited[parent] = struct{}{}
+
+		m, err := v.templateRepo.FindByName(ctx, parent)
+		if err != nil {
+			if IsOneOfNotFound(err) {
+				return &TemplateSyntaxError{
+					Name:    current.name,
+					Line:    1,
+					Column:  loc + 1,
+					Message: fmt.Sprintf("extended template %q not found", parent),
+				}
+			}
+			return err
+		}
+
+		current = source{name: m.Name, content: m.Content}
+		chain = append(chain, current)
+	}
+
+	sources := make(map[string]string, len(chain))
+	t := template.New(name).Funcs(v.funcMap)
+
+	// parse from the root layout, so the blocks of the descendants override it
+	for i := len(chain) - 1; i >= 0; i-- {
+		src := stripExtends(chain[i].content)
+		sources[chain[i].name] = src
+
+		if _, err := t.New(chain[i].name).Parse(src); err != nil {
+			return newTemplateSyntaxError(chain[i].name, sources, err)
+		}
+	}
+	return nil
+}
+
+// extendsOf returns the name of the extended template and the offset of its directive.
+func extendsOf(content string) (string, int) {
+	loc := reExtends.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return "", 0
+	}
+	return content[loc[2]:loc[3]], strings.Index(content, "{{")
+}
+
+// stripExtends blanks the extends directive, keeping the lines and columns of the content.
+func stripExtends(content string) string {
+	loc := reExtends.FindStringIndex(content)
+	if loc == nil {
+		return content
+	}
+	return content[:loc[0]] + strings.Repeat(" ", loc[1]-loc[0]) + content[loc[1]:]
+}
+
+type TemplateSyntaxError struct {
+	Name    string `json:"name" yaml:"name"`
+	Line    int    `json:"line" yaml:"line"`
+	Column  int    `json:"column" yaml:"column"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (e *TemplateSyntaxError) Error() string {
+	return fmt.Sprintf("template %s:%d:%d: %s", e.Name, e.Line, e.Column, e.Message)
+}
+
+func (e *TemplateSyntaxError) Unwrap() error {
+	return ErrTemplateSyntax
+}
+
+// newTemplateSyntaxError converts the parse error to *TemplateSyntaxError.
+// The parser reports the line only, so the column is the position of the quoted token of the message when found.
+func newTemplateSyntaxError(name string, sources map[string]string, err error) *TemplateSyntaxError {
+	e := &TemplateSyntaxError{Name: name, Message: err.Error()}
+
+	m := reParseError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return e
+	}
+
+	e.Message = m[4]
+	if _, ok := sources[m[1]]; ok {
+		e.Name = m[1]
+	}
+	e.Line, _ = strconv.Atoi(m[2])
+	e.Column, _ = strconv.Atoi(m[3])
+
+	if e.Column == 0 {
+		e.Column = 1
+
+		lines := strings.Split(sources[e.Name], "\n")
+		if e.Line > 0 && e.Line <= len(lines) {
+			line := lines[e.Line-1]
+			if token := reQuotedToken.FindStringSubmatch(e.Message); token != nil {
+				if i := strings.Index(line, token[1]+token[2]); i >= 0 {
+					e.Column = i + 1
+				}
+			} else if i := strings.Index(line, "{{"); i >= 0 {
+				e.Column = i + 1
+			}
+		}
+	}
+	return e
+}
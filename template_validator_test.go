@@ -0,0 +1,67 @@
+package pages
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"html/template"
+	"testing"
+
+	"github.com/gowool/theme"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+)
+
+func TestTemplateValidatorValidate(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewTemplateRepository(sql.ErrNoRows)
+	for _, m := range []model.Template{
+		{Name: "layout.html", Content: `<html>{{ block "content" . }}{{ end }}</html>`},
+		{Name: "loop.html", Content: `{{ extends "loop.html" }}`},
+	} {
+		if err := repo.Create(ctx, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	funcMaps := []theme.FuncMap{func(theme.Theme) template.FuncMap {
+		return template.FuncMap{"menu": func(string) string { return "" }}
+	}}
+	validator := NewDefaultTemplateValidator(repo, funcMaps)
+
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+		message string
+	}{
+		{name: "valid", content: `{{ extends "layout.html" }}{{ define "content" }}{{ menu "main" }}{{ end }}`},
+		{name: "unclosed", content: "<p>\n{{ if .X }}", line: 2, column: 1, message: "unexpected EOF"},
+		{name: "unknown func", content: `<p>{{ nav "main" }}</p>`, line: 1, column: 7, message: `function "nav" not defined`},
+		{name: "missing layout", content: `{{ extends "base.html" }}`, line: 1, column: 1, message: `extended template "base.html" not found`},
+		{name: "cycle", content: `{{ extends "loop.html" }}`, line: 1, column: 1, message: `template "loop.html" extends itself`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(ctx, "page.html", tt.content)
+
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+
+			var syntaxErr *TemplateSyntaxError
+			if !errors.As(err, &syntaxErr) || !errors.Is(err, ErrTemplateSyntax) {
+				t.Fatalf("err = %v, want *TemplateSyntaxError", err)
+			}
+			if syntaxErr.Line != tt.line || syntaxErr.Column != tt.column || syntaxErr.Message != tt.message {
+				t.Errorf("err = %d:%d %q, want %d:%d %q", syntaxErr.Line, syntaxErr.Column, syntaxErr.Message, tt.line, tt.column, tt.message)
+			}
+		})
+	}
+}
@@ -31,6 +31,7 @@ func (fm *FuncMap) FuncMap(_ theme.Theme) template.FuncMap {
 		"reverse_title_tag": reverseTitleTag,
 		"title_tag":         titleTag,
 		"meta_tags":         metaTags,
+		"attrs":             attrs,
 		"html_attrs":        htmlAttrs,
 		"head_attrs":        headAttrs,
 		"body_attrs":        bodyAttrs,
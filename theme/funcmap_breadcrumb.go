@@ -0,0 +1,119 @@
+package theme
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"maps"
+	"strings"
+
+	"github.com/gowool/theme"
+
+	"github.com/gowool/pages"
+)
+
+type FuncMapBreadcrumb struct {
+	builder pages.BreadcrumbBuilder
+}
+
+func NewFuncMapBreadcrumb(builder pages.BreadcrumbBuilder) *FuncMapBreadcrumb {
+	return &FuncMapBreadcrumb{
+		builder: builder,
+	}
+}
+
+func (fm *FuncMapBreadcrumb) FuncMap(t theme.Theme) template.FuncMap {
+	return template.FuncMap{
+		"breadcrumbs":         fm.breadcrumbs(t),
+		"breadcrumbs_menu":    fm.breadcrumbsMenu(t),
+		"breadcrumb_items":    fm.items,
+		"breadcrumbs_json_ld": fm.jsonLD,
+	}
+}
+
+func (fm *FuncMapBreadcrumb) breadcrumbs(t theme.Theme) func(context.Context, string, map[string]any) template.HTML {
+	return func(ctx context.Context, templateName string, data map[string]any) template.HTML {
+		return fm.render(ctx, t, templateName, data, fm.items(ctx))
+	}
+}
+
+func (fm *FuncMapBreadcrumb) breadcrumbsMenu(t theme.Theme) func(context.Context, string, string, map[string]any) template.HTML {
+	return func(ctx context.Context, handle, templateName string, data map[string]any) template.HTML {
+		return fm.render(ctx, t, templateName, data, fm.items(ctx, handle))
+	}
+}
+
+func (fm *FuncMapBreadcrumb) render(ctx context.Context, t theme.Theme, templateName string, data map[string]any, items []pages.BreadcrumbItem) template.HTML {
+	if len(items) == 0 {
+		return ""
+	}
+
+	data = maps.Clone(data)
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["breadcrumbs"] = items
+
+	str, err := t.HTML(ctx, templateName, data)
+	if err != nil {
+		return ""
+	}
+	return template.HTML(str)
+}
+
+// items returns the trail derived from the current page, or from the menu when the handle is given,
+// followed by the items appended by controllers and templates.
+func (fm *FuncMapBreadcrumb) items(ctx context.Context, handle ...string) []pages.BreadcrumbItem {
+	var (
+		items []pages.BreadcrumbItem
+		err   error
+	)
+	if len(handle) > 0 && handle[0] != "" {
+		items, err = fm.builder.FromMenu(ctx, handle[0])
+	} else {
+		items, err = fm.builder.FromPage(ctx, pages.CtxPage(ctx))
+	}
+	if err != nil {
+		items = nil
+	}
+	return append(items, pages.CtxBreadcrumbs(ctx).Items()...)
+}
+
+func (fm *FuncMapBreadcrumb) jsonLD(ctx context.Context, handle ...string) template.HTML {
+	items := fm.items(ctx, handle...)
+	if len(items) == 0 {
+		return ""
+	}
+
+	var base string
+	if site := pages.CtxSite(ctx); site != nil {
+		base = strings.TrimSuffix(strings.TrimSuffix(site.URL(), site.RelativePath), "/")
+	}
+
+	elements := make([]map[string]any, 0, len(items))
+	for i, item := range items {
+		element := map[string]any{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"name":     stripTags(item.Label),
+		}
+		if item.URL != "" {
+			if strings.HasPrefix(item.URL, "/") {
+				element["item"] = base + item.URL
+			} else {
+				element["item"] = item.URL
+			}
+		}
+		elements = append(elements, element)
+	}
+
+	raw, err := json.Marshal(map[string]any{
+		"@context":        "https://schema.org",
+		"@type":           "BreadcrumbList",
+		"itemListElement": elements,
+	})
+	if err != nil {
+		return ""
+	}
+	return template.HTML(`<script type="application/ld+json">` + string(raw) + "</script>")
+}
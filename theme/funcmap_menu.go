@@ -6,6 +6,7 @@ import (
 	"maps"
 
 	"github.com/gowool/theme"
+	"github.com/spf13/cast"
 
 	"github.com/gowool/pages"
 )
@@ -13,12 +14,14 @@ import (
 type FuncMapMenu struct {
 	menuService pages.Menu
 	matcher     pages.Matcher
+	menuCache   pages.MenuCache
 }
 
-func NewFuncMapMenu(menu pages.Menu, matcher pages.Matcher) *FuncMapMenu {
+func NewFuncMapMenu(menu pages.Menu, matcher pages.Matcher, menuCache pages.MenuCache) *FuncMapMenu {
 	return &FuncMapMenu{
 		menuService: menu,
 		matcher:     matcher,
+		menuCache:   menuCache,
 	}
 }
 
@@ -30,20 +33,70 @@ func (fm *FuncMapMenu) FuncMap(t theme.Theme) template.FuncMap {
 	}
 }
 
+// menu renders the menu with the template.
+// The result is cached unless the data has "cache" set to false, "cacheKey" varies the cache entry.
 func (fm *FuncMapMenu) menu(t theme.Theme) func(context.Context, string, string, map[string]any) template.HTML {
 	return func(ctx context.Context, handle, templateName string, data map[string]any) template.HTML {
-		m, err := fm.menuService.Get(ctx, handle)
-		if err != nil {
+		var key string
+		if fm.menuCache != nil && cast.ToBool(getOr(data, "cache", true)) {
+			key = fm.menuCache.Key(ctx, handle, "html", templateName,
+				data["maxDepth"], data["startLevel"], data["currentBranch"], data["display"], data["cacheKey"])
+
+			var str string
+			if fm.menuCache.Get(ctx, key, &str) {
+				return template.HTML(str)
+			}
+		}
+
+		m, err := fm.menuService.Get(ctx, handle, menuOptions(data)...)
+		if err != nil || m.Node == nil {
 			return ""
 		}
 
 		data = maps.Clone(data)
+		if data == nil {
+			data = map[string]any{}
+		}
+		if _, ok := data["ctx"]; !ok {
+			data["ctx"] = ctx
+		}
+		data["menu"] = m
 		data["node"] = m.Node
 
 		str, err := t.HTML(ctx, templateName, data)
 		if err != nil {
 			return ""
 		}
+
+		if key != "" {
+			fm.menuCache.Set(ctx, key, str, m)
+		}
 		return template.HTML(str)
 	}
 }
+
+func getOr(data map[string]any, key string, def any) any {
+	if value, ok := data[key]; ok {
+		return value
+	}
+	return def
+}
+
+// menuOptions reads the menu options from the template data:
+// "maxDepth", "startLevel", "currentBranch" and "display".
+func menuOptions(data map[string]any) []pages.MenuOption {
+	var options []pages.MenuOption
+	if depth := cast.ToInt(data["maxDepth"]); depth > 0 {
+		options = append(options, pages.WithMenuMaxDepth(depth))
+	}
+	if level := cast.ToInt(data["startLevel"]); level > 0 {
+		options = append(options, pages.WithMenuStartLevel(level))
+	}
+	if cast.ToBool(data["currentBranch"]) {
+		options = append(options, pages.WithMenuOnlyCurrentBranch())
+	}
+	if cast.ToBool(data["display"]) {
+		options = append(options, pages.WithMenuDisplay())
+	}
+	return options
+}
@@ -3,7 +3,10 @@ package pages
 import (
 	"context"
 	neturl "net/url"
+	"slices"
+	"strings"
 
+	"github.com/gowool/pages/internal"
 	"github.com/gowool/pages/model"
 )
 
@@ -16,19 +19,202 @@ type Voter interface {
 	MatchNode(ctx context.Context, node *model.Node) *bool
 }
 
+// PrioritizedVoter is a voter that is asked before the voters with lower priority.
+// Voters that do not implement it have the priority 0.
+type PrioritizedVoter interface {
+	Voter
+	Priority() int
+}
+
+type priorityVoter struct {
+	Voter
+	priority int
+}
+
+func (v priorityVoter) Priority() int {
+	return v.priority
+}
+
+// WithVoterPriority overrides the priority of the voter.
+func WithVoterPriority(voter Voter, priority int) PrioritizedVoter {
+	if v, ok := voter.(priorityVoter); ok {
+		voter = v.Voter
+	}
+	return priorityVoter{Voter: voter, priority: priority}
+}
+
+func VoterPriority(voter Voter) int {
+	if v, ok := voter.(PrioritizedVoter); ok {
+		return v.Priority()
+	}
+	return 0
+}
+
+const (
+	// NodeMetadataMatchRegex is the node metadata key with the regular expression matched against the current url path.
+	NodeMetadataMatchRegex = "match_regex"
+
+	// NodeMetadataMatchRoute is the node metadata key with the comma separated route patterns the node is current for.
+	NodeMetadataMatchRoute = "match_route"
+)
+
+// URLVoter marks the node as current when its URI equals the current url path.
 type URLVoter struct{}
 
 func NewURLVoter() URLVoter {
 	return URLVoter{}
 }
 
+func (URLVoter) Priority() int {
+	return 300
+}
+
 func (URLVoter) MatchNode(ctx context.Context, node *model.Node) *bool {
-	if u, ok := ctx.Value("url").(neturl.URL); ok && u.Path == node.URI {
+	path, ok := nodePath(node)
+	if !ok {
+		return nil
+	}
+
+	if u := CtxURL(ctx); u.Path != "" && trimSlash(u.Path) == trimSlash(path) {
+		return &ok
+	}
+	return nil
+}
+
+// PrefixVoter marks the node as current when the current url path is below the node URI,
+// e.g. the node "/blog" is current for "/blog/post-1".
+type PrefixVoter struct{}
+
+func NewPrefixVoter() PrefixVoter {
+	return PrefixVoter{}
+}
+
+func (PrefixVoter) Priority() int {
+	return 0
+}
+
+func (PrefixVoter) MatchNode(ctx context.Context, node *model.Node) *bool {
+	path, ok := nodePath(node)
+	if !ok {
+		return nil
+	}
+
+	prefix := trimSlash(path)
+	if prefix == "" {
+		// the home page would be the prefix of any path
+		return nil
+	}
+
+	current := trimSlash(CtxURL(ctx).Path)
+	if current == prefix || strings.HasPrefix(current, prefix+"/") {
+		return &ok
+	}
+	return nil
+}
+
+// RegexVoter marks the node as current when the current url path matches
+// the regular expression from the node metadata.
+type RegexVoter struct{}
+
+func NewRegexVoter() RegexVoter {
+	return RegexVoter{}
+}
+
+func (RegexVoter) Priority() int {
+	return 200
+}
+
+func (RegexVoter) MatchNode(ctx context.Context, node *model.Node) *bool {
+	expr := node.Metadata[NodeMetadataMatchRegex]
+	if expr == "" {
+		return nil
+	}
+
+	re, ok := internal.Regexp(expr)
+	if !ok {
+		return nil
+	}
+
+	if ok, _ = re.MatchString(CtxURL(ctx).Path); ok {
+		return &ok
+	}
+	return nil
+}
+
+// RoutePatternVoter marks the node as current when the pattern of the matched echo route
+// is listed in the node metadata.
+type RoutePatternVoter struct{}
+
+func NewRoutePatternVoter() RoutePatternVoter {
+	return RoutePatternVoter{}
+}
+
+func (RoutePatternVoter) Priority() int {
+	return 100
+}
+
+func (RoutePatternVoter) MatchNode(ctx context.Context, node *model.Node) *bool {
+	patterns := node.Metadata[NodeMetadataMatchRoute]
+	if patterns == "" {
+		return nil
+	}
+
+	current := CtxRoutePattern(ctx)
+	if current == "" {
+		return nil
+	}
+
+	for _, pattern := range strings.Split(patterns, ",") {
+		if ok := strings.TrimSpace(pattern) == current; ok {
+			return &ok
+		}
+	}
+	return nil
+}
+
+// PageVoter marks the nodes linked to the current page as current.
+type PageVoter struct{}
+
+func NewPageVoter() PageVoter {
+	return PageVoter{}
+}
+
+func (PageVoter) Priority() int {
+	return 400
+}
+
+func (PageVoter) MatchNode(ctx context.Context, node *model.Node) *bool {
+	page := CtxPage(ctx)
+	if page == nil || page.ID <= 0 {
+		return nil
+	}
+
+	ok := (node.Page != nil && node.Page.ID == page.ID) ||
+		(node.PageID != nil && *node.PageID == page.ID) ||
+		(page.Alias != "" && node.PageAlias() == page.Alias)
+	if ok {
 		return &ok
 	}
 	return nil
 }
 
+// nodePath returns the path of the node URI, skipping the nodes without a local link.
+func nodePath(node *model.Node) (string, bool) {
+	if node.URI == "" || node.PageAlias() != "" {
+		return "", false
+	}
+
+	u, err := neturl.Parse(node.URI)
+	if err != nil || u.Path == "" {
+		return "", false
+	}
+	return u.Path, true
+}
+
+func trimSlash(path string) string {
+	return strings.TrimSuffix(path, "/")
+}
+
 // Matcher represents an interface for matching nodes.
 // It provides methods for checking whether a node is current or an ancestor.
 type Matcher interface {
@@ -44,6 +230,10 @@ type DefaultMatcher struct {
 }
 
 func NewDefaultMatcher(voters ...Voter) DefaultMatcher {
+	voters = slices.Clone(voters)
+	slices.SortStableFunc(voters, func(a, b Voter) int {
+		return VoterPriority(b) - VoterPriority(a)
+	})
 	return DefaultMatcher{voters: voters}
 }
 
@@ -0,0 +1,96 @@
+package pages
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/gowool/pages/internal"
+	"github.com/gowool/pages/model"
+)
+
+func voterContext(path string) context.Context {
+	return WithURL(context.Background(), url.URL{Path: path})
+}
+
+func TestVoters(t *testing.T) {
+	page := &model.Page{ID: 7, Alias: "_page_alias_blog"}
+
+	tests := []struct {
+		name  string
+		voter Voter
+		ctx   context.Context
+		node  model.Node
+		want  *bool
+	}{
+		{name: "url", voter: NewURLVoter(), ctx: voterContext("/blog/"), node: model.Node{URI: "/blog"}, want: internal.Ptr(true)},
+		{name: "url other", voter: NewURLVoter(), ctx: voterContext("/news"), node: model.Node{URI: "/blog"}},
+		{name: "url alias", voter: NewURLVoter(), ctx: voterContext("/blog"), node: model.Node{URI: "_page_alias_blog"}},
+		{name: "prefix", voter: NewPrefixVoter(), ctx: voterContext("/blog/post-1"), node: model.Node{URI: "/blog"}, want: internal.Ptr(true)},
+		{name: "prefix partial segment", voter: NewPrefixVoter(), ctx: voterContext("/blogger"), node: model.Node{URI: "/blog"}},
+		{name: "prefix home", voter: NewPrefixVoter(), ctx: voterContext("/blog"), node: model.Node{URI: "/"}},
+		{name: "regex", voter: NewRegexVoter(), ctx: voterContext("/blog/2024/post"), node: model.Node{Metadata: map[string]string{NodeMetadataMatchRegex: `^/blog/\d+/`}}, want: internal.Ptr(true)},
+		{name: "regex invalid", voter: NewRegexVoter(), ctx: voterContext("/blog"), node: model.Node{Metadata: map[string]string{NodeMetadataMatchRegex: `(`}}},
+		{name: "route", voter: NewRoutePatternVoter(), ctx: WithRoutePattern(context.Background(), "/blog/:slug"), node: model.Node{Metadata: map[string]string{NodeMetadataMatchRoute: "/news, /blog/:slug"}}, want: internal.Ptr(true)},
+		{name: "page id", voter: NewPageVoter(), ctx: WithPage(context.Background(), page), node: model.Node{PageID: internal.Ptr[int64](7)}, want: internal.Ptr(true)},
+		{name: "page alias", voter: NewPageVoter(), ctx: WithPage(context.Background(), page), node: model.Node{URI: "_page_alias_blog"}, want: internal.Ptr(true)},
+		{name: "page other", voter: NewPageVoter(), ctx: WithPage(context.Background(), page), node: model.Node{PageID: internal.Ptr[int64](8)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.voter.MatchNode(tt.ctx, &tt.node)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("MatchNode() = %v, want %v", fmtBool(got), fmtBool(tt.want))
+			}
+		})
+	}
+}
+
+func fmtBool(b *bool) string {
+	if b == nil {
+		return "nil"
+	}
+	if *b {
+		return "true"
+	}
+	return "false"
+}
+
+type fixedVoter struct {
+	current bool
+}
+
+func (v fixedVoter) MatchNode(context.Context, *model.Node) *bool {
+	return &v.current
+}
+
+func TestMatcherAsksVotersByPriority(t *testing.T) {
+	ctx := context.Background()
+
+	matcher := NewDefaultMatcher(fixedVoter{current: true}, WithVoterPriority(fixedVoter{current: false}, 10))
+	if matcher.IsCurrent(ctx, &model.Node{}) {
+		t.Error("the voter with the higher priority is not asked first")
+	}
+
+	matcher = NewDefaultMatcher(fixedVoter{current: true}, WithVoterPriority(fixedVoter{current: false}, -10))
+	if !matcher.IsCurrent(ctx, &model.Node{}) {
+		t.Error("the voter with the lower priority is asked first")
+	}
+}
+
+func TestMatcherIsAncestor(t *testing.T) {
+	matcher := NewDefaultMatcher(NewURLVoter())
+
+	leaf := &model.Node{URI: "/blog/post"}
+	parent := &model.Node{URI: "/blog", Children: []*model.Node{leaf}}
+	root := &model.Node{Children: []*model.Node{parent}}
+
+	ctx := voterContext("/blog/post")
+	if !matcher.IsAncestor(ctx, root) || !matcher.IsAncestor(ctx, parent) {
+		t.Error("the ancestors of the current node are not matched")
+	}
+	if matcher.IsAncestor(ctx, leaf) {
+		t.Error("the current node is matched as its own ancestor")
+	}
+}
@@ -0,0 +1,454 @@
+package pages
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gowool/cr"
+	"go.uber.org/zap"
+
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+const (
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+
+	// WebhookTimestampTolerance is the default tolerance of VerifyWebhookRequest.
+	WebhookTimestampTolerance = 5 * time.Minute
+
+	webhookResponseLimit = 4 << 10
+)
+
+var _ Webhooks = (*DefaultWebhooks)(nil)
+
+type Webhooks interface {
+	// HandleEvent logs a delivery of the event for each matching webhook and delivers them in the background
+	// once the unit of work of the context is committed.
+	EventSubscriber
+
+	// Start resumes the pending deliveries left by the previous run and sweeps the due ones periodically,
+	// so the deliveries whose background attempts were lost are retried.
+	Start(ctx context.Context) error
+
+	// Deliver makes one attempt to deliver the logged event and records its result.
+	Deliver(ctx context.Context, id int64) (model.WebhookDelivery, error)
+
+	// Replay logs the event of the delivery as a new delivery and makes its first attempt,
+	// the failed attempt is retried in the background.
+	Replay(ctx context.Context, id int64) (model.WebhookDelivery, error)
+
+	// Stop cancels the pending retries and the sweeps and waits for the running attempts.
+	Stop(ctx context.Context) error
+}
+
+type DefaultWebhooks struct {
+	webhookRepo  repository.Webhook
+	deliveryRepo repository.WebhookDelivery
+	client       *http.Client
+	logger       *zap.Logger
+
+	// MaxAttempts limits the attempts of a delivery.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, doubled for each next one up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	// SweepInterval is the period of the sweeps of the due pending deliveries.
+	SweepInterval time.Duration
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	inflight map[int64]struct{}
+}
+
+func NewDefaultWebhooks(
+	webhookRepo repository.Webhook,
+	deliveryRepo repository.WebhookDelivery,
+	client *http.Client,
+	logger *zap.Logger,
+) *DefaultWebhooks {
+	if webhookRepo == nil {
+		panic("webhook repository is not specified")
+	}
+	if deliveryRepo == nil {
+		panic("webhook delivery repository is not specified")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DefaultWebhooks{
+		webhookRepo:   webhookRepo,
+		deliveryRepo:  deliveryRepo,
+		client:        client,
+		logger:        logger,
+		MaxAttempts:   6,
+		Backoff:       time.Second,
+		MaxBackoff:    10 * time.Minute,
+		SweepInterval: time.Minute,
+		ctx:           ctx,
+		cancel:        cancel,
+		inflight:      make(map[int64]struct{}),
+	}
+}
+
+func (w *DefaultWebhooks) HandleEvent(ctx context.Context, event Event) error {
+	meta := event.Meta()
+
+	webhooks, err := w.webhookRepo.Find(ctx, cr.New().SetFilter(cr.Filter{
+		Conditions: []any{cr.Condition{Column: "enabled", Operator: cr.OpEqual, Value: true}},
+	}))
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if !webhook.Matches(meta.Entity, meta.Action, meta.SiteID) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		delivery := model.WebhookDelivery{
+			WebhookID: webhook.ID,
+			Event:     WebhookEventName(meta),
+			Payload:   string(payload),
+			Status:    model.WebhookDeliveryPending,
+			Created:   now,
+			Updated:   now,
+		}
+		if err = w.deliveryRepo.Create(ctx, &delivery); err != nil {
+			return err
+		}
+
+		// the attempt must not see the delivery before its row is committed
+		repository.AfterCommit(ctx, func(context.Context) {
+			w.retry(webhook, delivery, 0)
+		})
+	}
+	return nil
+}
+
+func (w *DefaultWebhooks) Start(ctx context.Context) error {
+	if err := w.sweep(ctx); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.sweep(w.ctx); err != nil {
+					w.logger.Error("webhook: sweep failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (w *DefaultWebhooks) Deliver(ctx context.Context, id int64) (model.WebhookDelivery, error) {
+	delivery, err := w.deliveryRepo.FindByID(ctx, id)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	webhook, err := w.webhookRepo.FindByID(ctx, delivery.WebhookID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	return w.attempt(ctx, webhook, delivery)
+}
+
+func (w *DefaultWebhooks) Replay(ctx context.Context, id int64) (model.WebhookDelivery, error) {
+	old, err := w.deliveryRepo.FindByID(ctx, id)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	now := time.Now().UTC()
+	delivery := model.WebhookDelivery{
+		WebhookID: old.WebhookID,
+		Event:     old.Event,
+		Payload:   old.Payload,
+		Status:    model.WebhookDeliveryPending,
+		Created:   now,
+		Updated:   now,
+	}
+	if err = w.deliveryRepo.Create(ctx, &delivery); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	webhook, err := w.webhookRepo.FindByID(ctx, delivery.WebhookID)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	if delivery, err = w.attempt(ctx, webhook, delivery); err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	if delivery.NextAttempt != nil {
+		w.retry(webhook, delivery, time.Until(*delivery.NextAttempt))
+	}
+	return delivery, nil
+}
+
+func (w *DefaultWebhooks) Stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sweep retries the pending deliveries which are due and have no background attempts,
+// like the ones of the previous run or the ones whose attempt failed to be recorded.
+func (w *DefaultWebhooks) sweep(ctx context.Context) error {
+	deliveries, err := w.deliveryRepo.Find(ctx, cr.New().SetFilter(cr.Filter{
+		Operator: cr.OpAND,
+		Conditions: []any{
+			cr.Condition{Column: "status", Operator: cr.OpEqual, Value: model.WebhookDeliveryPending},
+			cr.Filter{
+				Operator: cr.OpOR,
+				Conditions: []any{
+					cr.Condition{Column: "next_attempt", Operator: cr.OpIS},
+					cr.Condition{Column: "next_attempt", Operator: cr.OpLte, Value: time.Now().UTC()},
+				},
+			},
+		},
+	}))
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if w.running(delivery.ID) {
+			continue
+		}
+
+		webhook, err := w.webhookRepo.FindByID(ctx, delivery.WebhookID)
+		if err != nil {
+			if !IsOneOfNotFound(err) {
+				return err
+			}
+
+			// the webhook is deleted, nothing is left to deliver to
+			delivery.Status = model.WebhookDeliveryFailed
+			delivery.Error = "webhook: not found"
+			delivery.NextAttempt = nil
+			delivery.Updated = time.Now().UTC()
+			if err = w.deliveryRepo.Update(ctx, &delivery); err != nil {
+				return err
+			}
+			continue
+		}
+
+		w.retry(webhook, delivery, 0)
+	}
+	return nil
+}
+
+func (w *DefaultWebhooks) running(id int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, ok := w.inflight[id]
+	return ok
+}
+
+// retry makes the attempts of the delivery in the background until it succeeds or fails.
+// A delivery has one background loop at most, the next ones are ignored while it runs.
+func (w *DefaultWebhooks) retry(webhook model.Webhook, delivery model.WebhookDelivery, delay time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.inflight[delivery.ID]; ok {
+		return
+	}
+	w.inflight[delivery.ID] = struct{}{}
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+		defer func() {
+			w.mu.Lock()
+			delete(w.inflight, delivery.ID)
+			w.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-w.ctx.Done():
+				return
+			case <-time.After(max(delay, 0)):
+			}
+
+			var err error
+			if delivery, err = w.attempt(w.ctx, webhook, delivery); err != nil {
+				w.logger.Error("webhook: delivery failed", zap.Int64("id", delivery.ID), zap.Error(err))
+				return
+			}
+			if delivery.NextAttempt == nil {
+				if delivery.Status == model.WebhookDeliveryFailed {
+					w.logger.Warn("webhook: delivery attempts exhausted", zap.Int64("id", delivery.ID), zap.String("error", delivery.Error))
+				}
+				return
+			}
+			delay = time.Until(*delivery.NextAttempt)
+		}
+	}()
+}
+
+// attempt sends the delivery and records the result of the attempt.
+func (w *DefaultWebhooks) attempt(ctx context.Context, webhook model.Webhook, delivery model.WebhookDelivery) (model.WebhookDelivery, error) {
+	status, body, err := w.send(ctx, webhook, delivery)
+
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	delivery.Error = ""
+	delivery.NextAttempt = nil
+	delivery.Updated = now
+
+	switch {
+	case err == nil:
+		delivery.Status = model.WebhookDeliverySucceeded
+	case delivery.Attempts >= w.MaxAttempts:
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Status = model.WebhookDeliveryPending
+		delivery.Error = err.Error()
+		next := now.Add(w.backoff(delivery.Attempts))
+		delivery.NextAttempt = &next
+	}
+
+	if err = w.deliveryRepo.Update(ctx, &delivery); err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (w *DefaultWebhooks) send(ctx context.Context, webhook model.Webhook, delivery model.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookHeaderEvent, delivery.Event)
+	req.Header.Set(WebhookHeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if webhook.Secret != "" {
+		req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(webhook.Secret, timestamp, body))
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, webhookResponseLimit))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, string(raw), fmt.Errorf("webhook: unexpected response status %d", res.StatusCode)
+	}
+	return res.StatusCode, string(raw), nil
+}
+
+func (w *DefaultWebhooks) backoff(attempts int) time.Duration {
+	d := w.Backoff
+	for i := 1; i < attempts && d < w.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, w.MaxBackoff)
+}
+
+func WebhookEventName(meta EventMeta) string {
+	return meta.Entity + "." + meta.Action
+}
+
+// SignWebhookPayload returns the signature of the webhook request, "sha256=" followed by
+// the hex encoded HMAC-SHA256 of the timestamp and the body joined by a dot.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "%d.", timestamp)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookRequest checks the signature of the webhook request received by the subscriber.
+// The requests signed more than tolerance before or after now are rejected, so a captured request
+// cannot be replayed later. A non-positive tolerance means WebhookTimestampTolerance.
+func VerifyWebhookRequest(secret string, r *http.Request, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(r.Header.Get(WebhookHeaderTimestamp), 10, 64)
+	if err != nil {
+		return errors.New("webhook: invalid timestamp")
+	}
+
+	if tolerance <= 0 {
+		tolerance = WebhookTimestampTolerance
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); age > tolerance || age < -tolerance {
+		return errors.New("webhook: stale timestamp")
+	}
+
+	signature := r.Header.Get(WebhookHeaderSignature)
+	if !hmac.Equal([]byte(signature), []byte(SignWebhookPayload(secret, timestamp, body))) {
+		return errors.New("webhook: invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package pages
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gowool/pages/internal/memory"
+	"github.com/gowool/pages/model"
+	"github.com/gowool/pages/repository"
+)
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+	at     time.Time
+}
+
+// webhookServer answers the requests with the statuses in turn, the last one repeated.
+type webhookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []webhookRequest
+}
+
+func newWebhookServer(t *testing.T, statuses ...int) *webhookServer {
+	t.Helper()
+
+	s := &webhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, webhookRequest{header: r.Header.Clone(), body: body, at: time.Now()})
+		status := s.statuses[min(len(s.requests), len(s.statuses))-1]
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookServer) received() []webhookRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]webhookRequest(nil), s.requests...)
+}
+
+type testWebhooks struct {
+	*DefaultWebhooks
+	webhookRepo  *memory.Repository[model.Webhook]
+	deliveryRepo *memory.Repository[model.WebhookDelivery]
+}
+
+func newTestWebhooks(t *testing.T, url string) testWebhooks {
+	t.Helper()
+
+	webhookRepo := memory.NewRepository[model.Webhook](sql.ErrNoRows)
+	deliveryRepo := memory.NewRepository[model.WebhookDelivery](ErrWebhookDeliveryNotFound)
+
+	w := NewDefaultWebhooks(webhookRepo, deliveryRepo, nil, nil)
+	w.Backoff = 20 * time.Millisecond
+	w.MaxBackoff = 40 * time.Millisecond
+	w.SweepInterval = 20 * time.Millisecond
+	t.Cleanup(func() { _ = w.Stop(context.Background()) })
+
+	if err := webhookRepo.Create(context.Background(), &model.Webhook{Name: "hook", URL: url, Secret: "secret", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	return testWebhooks{DefaultWebhooks: w, webhookRepo: webhookRepo, deliveryRepo: deliveryRepo}
+}
+
+// settled waits for the delivery to succeed or fail.
+func (w testWebhooks) settled(t *testing.T, id int64) model.WebhookDelivery {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if delivery, err := w.deliveryRepo.FindByID(context.Background(), id); err == nil && delivery.Status != model.WebhookDeliveryPending {
+			return delivery
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("delivery %d is still pending", id)
+	return model.WebhookDelivery{}
+}
+
+func testWebhookEvent(ctx context.Context) Event {
+	page := model.Page{ID: 1, SiteID: 1, Name: "About"}
+	return NewEntityEvent(ctx, EventEntityPage, EventActionCreated, page.ID, &page.SiteID, nil, &page)
+}
+
+func TestWebhooksSignDeliveriesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	server := newWebhookServer(t, http.StatusOK)
+	w := newTestWebhooks(t, server.URL)
+	uow := repository.NewHookedUnitOfWork(&memory.UnitOfWork{})
+
+	if err := uow.Do(ctx, func(ctx context.Context) error {
+		if err := w.HandleEvent(ctx, testWebhookEvent(ctx)); err != nil {
+			return err
+		}
+		time.Sleep(20 * time.Millisecond)
+		if len(server.received()) > 0 {
+			t.Errorf("delivered before commit")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := w.settled(t, 1)
+	if delivery.Status != model.WebhookDeliverySucceeded || delivery.Attempts != 1 {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+
+	requests := server.received()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(requests))
+	}
+	req := requests[0]
+	if req.header.Get(WebhookHeaderEvent) != "page.created" || req.header.Get(WebhookHeaderDelivery) != "1" {
+		t.Errorf("headers = %v", req.header)
+	}
+
+	timestamp, _ := strconv.ParseInt(req.header.Get(WebhookHeaderTimestamp), 10, 64)
+	if got, want := req.header.Get(WebhookHeaderSignature), SignWebhookPayload("secret", timestamp, req.body); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(req.body))
+	r.Header = req.header
+	if err := VerifyWebhookRequest("secret", r, req.body, 0); err != nil {
+		t.Errorf("verify err = %v", err)
+	}
+}
+
+func TestWebhooksRetryWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	server := newWebhookServer(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+	w := newTestWebhooks(t, server.URL)
+
+	if err := w.HandleEvent(ctx, testWebhookEvent(ctx)); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := w.settled(t, 1)
+	if delivery.Status != model.WebhookDeliverySucceeded || delivery.Attempts != 3 {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+
+	requests := server.received()
+	for i, want := range []time.Duration{w.Backoff, 2 * w.Backoff} {
+		if got := requests[i+1].at.Sub(requests[i].at); got < want {
+			t.Errorf("retry %d after %v, want at least %v", i+1, got, want)
+		}
+	}
+}
+
+func TestWebhooksFailAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	server := newWebhookServer(t, http.StatusServiceUnavailable)
+	w := newTestWebhooks(t, server.URL)
+	w.MaxAttempts = 3
+
+	if err := w.HandleEvent(ctx, testWebhookEvent(ctx)); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := w.settled(t, 1)
+	if delivery.Status != model.WebhookDeliveryFailed || delivery.Attempts != 3 {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+	if delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.Error == "" || delivery.NextAttempt != nil {
+		t.Errorf("delivery = %+v", delivery)
+	}
+
+	time.Sleep(3 * w.MaxBackoff)
+	if n := len(server.received()); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+}
+
+func TestWebhooksReplay(t *testing.T) {
+	ctx := context.Background()
+	server := newWebhookServer(t, http.StatusInternalServerError, http.StatusOK)
+	w := newTestWebhooks(t, server.URL)
+	w.MaxAttempts = 1
+
+	if err := w.HandleEvent(ctx, testWebhookEvent(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	failed := w.settled(t, 1)
+	if failed.Status != model.WebhookDeliveryFailed {
+		t.Fatalf("delivery = %+v", failed)
+	}
+
+	replayed, err := w.Replay(ctx, failed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.ID == failed.ID || replayed.Status != model.WebhookDeliverySucceeded {
+		t.Errorf("replayed = %+v", replayed)
+	}
+	if replayed.Payload != failed.Payload || replayed.Event != failed.Event {
+		t.Errorf("replayed a different event")
+	}
+
+	if old, _ := w.deliveryRepo.FindByID(ctx, failed.ID); old.Status != model.WebhookDeliveryFailed || old.Attempts != 1 {
+		t.Errorf("the replayed delivery changed: %+v", old)
+	}
+}
+
+func TestWebhooksSweepPendingDeliveries(t *testing.T) {
+	ctx := context.Background()
+	server := newWebhookServer(t, http.StatusOK)
+	w := newTestWebhooks(t, server.URL)
+
+	// left by the previous run, the second one belongs to a deleted webhook
+	past := time.Now().UTC().Add(-time.Minute)
+	for _, delivery := range []model.WebhookDelivery{
+		{WebhookID: 1, Event: "page.created", Payload: "{}", Status: model.WebhookDeliveryPending, Attempts: 1, NextAttempt: &past},
+		{WebhookID: 2, Event: "page.created", Payload: "{}", Status: model.WebhookDeliveryPending},
+	} {
+		if err := w.deliveryRepo.Create(ctx, &delivery); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if delivery := w.settled(t, 1); delivery.Status != model.WebhookDeliverySucceeded || delivery.Attempts != 2 {
+		t.Errorf("delivery = %+v", delivery)
+	}
+	if delivery := w.settled(t, 2); delivery.Status != model.WebhookDeliveryFailed {
+		t.Errorf("delivery of the deleted webhook = %+v", delivery)
+	}
+
+	// a delivery whose background attempt was lost is picked by the next sweep
+	if err := w.deliveryRepo.Create(ctx, &model.WebhookDelivery{WebhookID: 1, Event: "page.updated", Payload: "{}", Status: model.WebhookDeliveryPending}); err != nil {
+		t.Fatal(err)
+	}
+	if delivery := w.settled(t, 3); delivery.Status != model.WebhookDeliverySucceeded {
+		t.Errorf("delivery = %+v", delivery)
+	}
+	if n := len(server.received()); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+func TestVerifyWebhookRequest(t *testing.T) {
+	body := []byte(`{"entity":"page"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		age       time.Duration
+		tolerance time.Duration
+		ok        bool
+	}{
+		{name: "valid", secret: "secret", ok: true},
+		{name: "invalid signature", secret: "other"},
+		{name: "stale", secret: "secret", age: 10 * time.Minute},
+		{name: "future", secret: "secret", age: -10 * time.Minute},
+		{name: "within tolerance", secret: "secret", age: 10 * time.Minute, tolerance: time.Hour, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timestamp := time.Now().Add(-tt.age).Unix()
+
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			r.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+			r.Header.Set(WebhookHeaderSignature, SignWebhookPayload(tt.secret, timestamp, body))
+
+			if err := VerifyWebhookRequest("secret", r, body, tt.tolerance); (err == nil) != tt.ok {
+				t.Errorf("err = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}